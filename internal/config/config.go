@@ -10,10 +10,58 @@ type Config struct {
 	MySQL struct {
 		DataSource string
 	}
-	Redis redis.RedisConf
-	Auth  struct {
-		AccessSecret string
-		AccessExpire int64
-	}
+	Redis              redis.RedisConf
+	Auth               AuthConf
 	AutoApproveClients []string
+	// 用户授权的有效期（秒），超过后需要重新确认，默认90天
+	ConsentExpire int64 `json:",default=7776000"`
+	// 授权服务器标识（issuer），元数据、动态注册和id_token都取自此地址，未配置时启动失败
+	Issuer string
+	// 客户端注册配置
+	Registration RegistrationConf `json:",optional"`
+	// 客户端缓存配置
+	ClientCache ClientCacheConf
+}
+
+// ClientCacheConf 客户端进程内缓存配置
+type ClientCacheConf struct {
+	// 缓存有效期（秒），其他实例修改客户端后最迟在过期后生效
+	Expire int64 `json:",default=60"`
+	// 最多缓存的客户端数量，超过后淘汰最久未使用的客户端
+	Limit int `json:",default=1000"`
+}
+
+// RegistrationConf 客户端注册配置，注册需要admin权限的访问令牌或初始访问令牌
+type RegistrationConf struct {
+	// 使用初始访问令牌注册的客户端需要管理员审批后才能使用
+	RequireApproval bool `json:",optional"`
+}
+
+// AuthConf 令牌签发配置
+type AuthConf struct {
+	AccessSecret string
+	AccessExpire int64
+	// 刷新令牌有效期（秒），默认30天
+	RefreshExpire int64 `json:",default=2592000"`
+	// 非对称签名配置，未配置私钥文件时使用AccessSecret进行HS256签名
+	SigningMethod  string `json:",default=RS256,options=RS256|RS384|RS512|PS256|ES256|ES384|ES512|EdDSA"`
+	PrivateKeyFile string `json:",optional"`
+	KeyID          string `json:",optional"`
+	// 额外的签名密钥，按NotBefore依次接替签名，旧密钥在RetireAfter之前仍可用于验证
+	Keys []SigningKeyConf `json:",optional"`
+	// 自动轮换间隔（秒），0表示不自动轮换
+	RotateInterval int64 `json:",optional"`
+	// 新密钥在启用前提前发布到JWKS的时长（秒），默认1天
+	RotatePrepublish int64 `json:",default=86400"`
+	// 加密保存到Redis的轮换私钥的密钥，base64编码的32字节，所有实例必须相同；未配置时不能轮换
+	KeyEncryptionKey string `json:",optional"`
+}
+
+// SigningKeyConf 签名密钥配置
+type SigningKeyConf struct {
+	KeyID          string `json:",optional"`
+	SigningMethod  string `json:",default=RS256,options=RS256|RS384|RS512|PS256|ES256|ES384|ES512|EdDSA"`
+	PrivateKeyFile string
+	NotBefore      string `json:",optional"` // 开始用于签名的时间，RFC3339格式
+	RetireAfter    string `json:",optional"` // 停止用于验证的时间，RFC3339格式
 }
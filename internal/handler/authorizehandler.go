@@ -1,29 +1,76 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 
 	"oauth2-server/internal/logic"
+	"oauth2-server/internal/oautherr"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
 
+	"github.com/go-session/session/v3"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
+// AuthorizeHandler 授权端点，未登录时跳转到登录页面，需要确认时跳转到授权页面，完成后携带授权码或错误重定向回客户端。
+// 只有客户端或回调地址无效时直接返回错误
 func AuthorizeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			oautherr.Write(w, err)
+			return
+		}
+
 		var req types.AuthorizeReq
-		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+		var action string
+		if r.Method == http.MethodPost {
+			// 授权页面提交的选择，恢复跳转前保存的授权请求
+			saved, ok := consentRequest(store, r)
+			if !ok {
+				http.Error(w, "invalid consent token", http.StatusForbidden)
+				return
+			}
+			req, action = saved, r.PostFormValue("action")
+		} else if err := httpx.Parse(r, &req); err != nil {
+			util.RenderErrorPage(w, oautherr.InvalidRequest, err.Error())
 			return
 		}
 
 		l := logic.NewAuthorizeLogic(r.Context(), svcCtx)
-		resp, err := l.Authorize(&req)
-		if err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
-		} else {
-			httpx.OkJsonCtx(r.Context(), w, resp)
+		resp, err := l.Authorize(&req, loggedInUserID(store), loginTime(store), action)
+		switch {
+		case errors.Is(err, logic.ErrLoginRequired):
+			// 登录后回到本次授权请求
+			store.Set(sessionReturnTo, r.URL.RequestURI())
+			store.Save()
+			http.Redirect(w, r, util.LoginPath, http.StatusFound)
+		case errors.Is(err, logic.ErrConsentRequired):
+			store.Set(sessionAuthorizeRequest, req)
+			store.Save()
+			http.Redirect(w, r, util.ConsentPath, http.StatusFound)
+		case err != nil:
+			// 客户端或回调地址无效，无法重定向回客户端，展示错误页面
+			if e := oautherr.From(err); e.Code != oautherr.ServerError {
+				util.RenderErrorPage(w, e.Code, e.Description)
+				return
+			}
+			oautherr.Write(w, err)
+		default:
+			location, err := util.AuthorizeRedirect(resp.RedirectURI, url.Values{
+				"code":              {resp.Code},
+				"error":             {resp.Error},
+				"error_description": {resp.ErrorDescription},
+				"state":             {resp.State},
+			})
+			if err != nil {
+				oautherr.Write(w, err)
+				return
+			}
+			http.Redirect(w, r, location, http.StatusFound)
 		}
 	}
 }
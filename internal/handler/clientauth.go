@@ -0,0 +1,11 @@
+package handler
+
+import "net/http"
+
+// applyBasicAuth 支持通过HTTP Basic认证（client_secret_basic）传递客户端凭证
+func applyBasicAuth(r *http.Request, clientID, clientSecret *string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		*clientID = id
+		*clientSecret = secret
+	}
+}
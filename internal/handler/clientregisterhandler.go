@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"oauth2-server/internal/logic"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
 
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
@@ -20,7 +22,9 @@ func ClientRegisterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 
 		l := logic.NewClientRegisterLogic(r.Context(), svcCtx)
 		resp, err := l.ClientRegister(&req)
-		if err != nil {
+		if errors.Is(err, util.ErrInvalidInitialAccessToken) {
+			util.WriteRegistrationError(w, err)
+		} else if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
 			httpx.OkJsonCtx(r.Context(), w, resp)
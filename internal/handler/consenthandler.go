@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	oauth2errors "github.com/go-oauth2/oauth2/v4/errors"
+	"github.com/go-session/session/v3"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ConsentHandler 授权页面，展示等待用户确认的授权请求
+func ConsentHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		if loggedInUserID(store) == "" {
+			http.Redirect(w, r, util.LoginPath, http.StatusFound)
+			return
+		}
+
+		saved, _ := store.Get(sessionAuthorizeRequest)
+		req, ok := saved.(types.AuthorizeReq)
+		if !ok {
+			http.Error(w, oauth2errors.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
+		}
+
+		l := logic.NewConsentLogic(r.Context(), svcCtx)
+		page, err := l.Consent(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		page.Token = uuid.New().String()
+		store.Set(sessionConsentToken, page.Token)
+		store.Save()
+
+		if err := util.RenderTemplate(w, "static/auth.html", page); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		}
+	}
+}
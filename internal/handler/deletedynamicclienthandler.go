@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func DeleteDynamicClientHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DynamicClientReq
+		if err := httpx.Parse(r, &req); err != nil {
+			util.WriteRegistrationError(w, &util.RegistrationError{
+				Code:        util.RegistrationErrInvalidClientMetadata,
+				Description: err.Error(),
+			})
+			return
+		}
+
+		l := logic.NewDeleteDynamicClientLogic(r.Context(), svcCtx)
+		err := l.DeleteDynamicClient(&req)
+		if err != nil {
+			util.WriteRegistrationError(w, err)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// DiscoveryHandler 同时服务于RFC 8414和OpenID Connect Discovery，routes在请求时读取以反映全部已注册路由
+func DiscoveryHandler(svcCtx *svc.ServiceContext, routes func() []rest.Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewDiscoveryLogic(r.Context(), svcCtx)
+		resp, err := l.Discovery(util.IssuerURL(svcCtx.Config.Issuer), routes())
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
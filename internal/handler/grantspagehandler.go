@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/go-session/session/v3"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// GrantsPageHandler 授权管理页面，用户查看和撤销已授权的应用
+func GrantsPageHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		userID := loggedInUserID(store)
+		if userID == "" {
+			store.Set(sessionReturnTo, util.GrantsPath)
+			store.Save()
+			http.Redirect(w, r, util.LoginPath, http.StatusFound)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			// 校验表单令牌，防止跨站撤销授权
+			token, _ := store.Get(sessionGrantsToken)
+			expected, _ := token.(string)
+			if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(r.PostFormValue("grants_token"))) != 1 {
+				http.Error(w, "invalid grants token", http.StatusForbidden)
+				return
+			}
+
+			l := logic.NewRevokeGrantLogic(r.Context(), svcCtx)
+			if err := l.RevokeGrant(userID, &types.GrantReq{ClientID: r.PostFormValue("client_id")}); err != nil {
+				httpx.ErrorCtx(r.Context(), w, err)
+				return
+			}
+			http.Redirect(w, r, util.GrantsPath, http.StatusFound)
+			return
+		}
+
+		l := logic.NewListGrantsLogic(r.Context(), svcCtx)
+		grants, err := l.ListGrants(userID)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		page := &util.GrantsPage{Grants: grants, Token: uuid.New().String()}
+		store.Set(sessionGrantsToken, page.Token)
+		store.Save()
+
+		if err := util.RenderTemplate(w, "static/grants.html", page); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		}
+	}
+}
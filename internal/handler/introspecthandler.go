@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/oautherr"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func IntrospectHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.IntrospectReq
+		if err := httpx.Parse(r, &req); err != nil {
+			oautherr.Write(w, oautherr.New(oautherr.InvalidRequest, err.Error()))
+			return
+		}
+		applyBasicAuth(r, &req.ClientID, &req.ClientSecret)
+
+		l := logic.NewIntrospectLogic(r.Context(), svcCtx)
+		resp, err := l.Introspect(&req)
+		if err != nil {
+			// 错误按RFC 6749 5.2输出
+			oautherr.Write(w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+
+	"github.com/go-session/session/v3"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ListGrantsHandler 返回当前登录用户已授权的应用
+func ListGrantsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		userID := loggedInUserID(store)
+		if userID == "" {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
+		}
+
+		l := logic.NewListGrantsLogic(r.Context(), svcCtx)
+		grants, err := l.ListGrants(userID)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		httpx.OkJsonCtx(r.Context(), w, map[string]interface{}{
+			"grants": grants,
+		})
+	}
+}
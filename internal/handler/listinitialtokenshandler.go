@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func ListInitialTokensHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewListInitialTokensLogic(r.Context(), svcCtx)
+		resp, err := l.ListInitialTokens()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/go-session/session/v3"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// LoginHandler 登录页面，登录成功后返回登录前的授权请求
+func LoginHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.ServeFile(w, r, "static/login.html")
+			return
+		}
+
+		var req types.LoginReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := logic.NewLoginLogic(r.Context(), svcCtx)
+		resp, err := l.Login(&req)
+		if errors.Is(err, util.ErrInvalidCredentials) {
+			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		store.Set(sessionUserID, resp.UserID)
+		store.Set(sessionLoginTime, time.Now().Unix())
+		location := util.ConsentPath
+		if v, ok := store.Get(sessionReturnTo); ok {
+			location = v.(string)
+			store.Delete(sessionReturnTo)
+		}
+		store.Save()
+
+		http.Redirect(w, r, location, http.StatusFound)
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/go-session/session/v3"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// RevokeGrantHandler 撤销当前登录用户对客户端的授权
+func RevokeGrantHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GrantReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		userID := loggedInUserID(store)
+		if userID == "" {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
+		}
+
+		l := logic.NewRevokeGrantLogic(r.Context(), svcCtx)
+		if err := l.RevokeGrant(userID, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
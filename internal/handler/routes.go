@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"oauth2-server/internal/svc"
+	"oauth2-server/internal/util"
 
 	"github.com/zeromicro/go-zero/rest"
 )
@@ -13,24 +14,172 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		[]rest.Route{
 			{
 				Method:  http.MethodPost,
-				Path:    "/api/client/register",
+				Path:    util.ClientRegisterPath,
 				Handler: ClientRegisterHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    util.DynamicRegisterPath,
+				Handler: RegisterDynamicClientHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.DynamicClientPath,
+				Handler: GetDynamicClientHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    util.DynamicClientPath,
+				Handler: UpdateDynamicClientHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    util.DynamicClientPath,
+				Handler: DeleteDynamicClientHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.LoginPath,
+				Handler: LoginHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    util.LoginPath,
+				Handler: LoginHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.ConsentPath,
+				Handler: ConsentHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.GrantsPath,
+				Handler: GrantsPageHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    util.GrantsPath,
+				Handler: GrantsPageHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
-				Path:    "/oauth/authorize",
+				Path:    util.GrantsAPIPath,
+				Handler: ListGrantsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    util.GrantAPIPath,
+				Handler: RevokeGrantHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.AuthorizePath,
 				Handler: AuthorizeHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/oauth/token",
+				Path:    util.AuthorizePath,
+				Handler: AuthorizeHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    util.TokenPath,
 				Handler: TokenHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    util.RevokePath,
+				Handler: RevokeHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    util.IntrospectPath,
+				Handler: IntrospectHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
-				Path:    "/oauth/userinfo",
+				Path:    util.UserInfoPath,
 				Handler: UserInfoHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.JwksPath,
+				Handler: JwksHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    util.OAuthMetadataPath,
+				Handler: DiscoveryHandler(serverCtx, server.Routes),
+			},
 		},
 	)
+
+	// 配置了Issuer并使用非对称密钥签名时才支持OpenID Connect
+	if util.OpenIDEnabled(serverCtx.Config.Issuer, serverCtx.KeySet) {
+		server.AddRoute(rest.Route{
+			Method:  http.MethodGet,
+			Path:    util.OpenIDConfigurationPath,
+			Handler: DiscoveryHandler(serverCtx, server.Routes),
+		})
+	}
+
+	// 客户端、初始访问令牌和签名密钥管理接口需要admin权限的访问令牌
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.AdminAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    util.ClientsPath,
+					Handler: ListClientsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    util.ClientPath,
+					Handler: GetClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    util.ClientPath,
+					Handler: UpdateClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    util.ClientPath,
+					Handler: DeleteClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    util.ClientSecretPath,
+					Handler: ResetClientSecretHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    util.ClientApprovePath,
+					Handler: ApproveClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    util.InitialTokensPath,
+					Handler: CreateInitialTokenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    util.InitialTokensPath,
+					Handler: ListInitialTokensHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    util.InitialTokenPath,
+					Handler: DeleteInitialTokenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    util.SigningKeyRotatePath,
+					Handler: RotateSigningKeyHandler(serverCtx),
+				},
+			}...,
+		),
+	)
 }
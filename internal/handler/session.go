@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/go-session/session/v3"
+)
+
+// 会话中保存的数据
+const (
+	sessionUserID           = "LoggedInUserID"   // 已登录的用户ID
+	sessionLoginTime        = "LoginTime"        // 用户登录的时间（Unix秒），作为ID Token的auth_time
+	sessionReturnTo         = "ReturnTo"         // 登录后返回的地址
+	sessionAuthorizeRequest = "AuthorizeRequest" // 等待用户确认的授权请求
+	sessionConsentToken     = "ConsentToken"     // 授权页面防跨站请求伪造的表单令牌
+	sessionGrantsToken      = "GrantsToken"      // 授权管理页面防跨站请求伪造的表单令牌
+)
+
+// loggedInUserID 获取会话中已登录的用户ID，未登录时为空
+func loggedInUserID(store session.Store) string {
+	uid, _ := store.Get(sessionUserID)
+	userID, _ := uid.(string)
+	return userID
+}
+
+// loginTime 获取会话中用户登录的时间，未记录时为0
+func loginTime(store session.Store) int64 {
+	t, _ := store.Get(sessionLoginTime)
+	authTime, _ := t.(int64)
+	return authTime
+}
+
+// consentRequest 校验授权页面提交的表单，取出跳转到授权页面前保存的授权请求
+func consentRequest(store session.Store, r *http.Request) (types.AuthorizeReq, bool) {
+	action := r.PostFormValue("action")
+	if action != util.ConsentApprove && action != util.ConsentReject {
+		return types.AuthorizeReq{}, false
+	}
+
+	token, _ := store.Get(sessionConsentToken)
+	expected, _ := token.(string)
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(r.PostFormValue("consent_token"))) != 1 {
+		return types.AuthorizeReq{}, false
+	}
+
+	saved, _ := store.Get(sessionAuthorizeRequest)
+	req, ok := saved.(types.AuthorizeReq)
+	if !ok {
+		return types.AuthorizeReq{}, false
+	}
+
+	store.Delete(sessionAuthorizeRequest)
+	store.Delete(sessionConsentToken)
+	return req, store.Save() == nil
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"oauth2-server/internal/logic"
+	"oauth2-server/internal/oautherr"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
 
@@ -14,14 +15,16 @@ func TokenHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.TokenReq
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			oautherr.Write(w, oautherr.New(oautherr.InvalidRequest, err.Error()))
 			return
 		}
+		applyBasicAuth(r, &req.ClientID, &req.ClientSecret)
 
 		l := logic.NewTokenLogic(r.Context(), svcCtx)
 		resp, err := l.Token(&req)
 		if err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			// 错误按RFC 6749 5.2输出
+			oautherr.Write(w, err)
 		} else {
 			httpx.OkJsonCtx(r.Context(), w, resp)
 		}
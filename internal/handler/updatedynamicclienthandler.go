@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/logic"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func UpdateDynamicClientHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DynamicClientUpdateReq
+		if err := httpx.Parse(r, &req); err != nil {
+			util.WriteRegistrationError(w, &util.RegistrationError{
+				Code:        util.RegistrationErrInvalidClientMetadata,
+				Description: err.Error(),
+			})
+			return
+		}
+
+		l := logic.NewUpdateDynamicClientLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateDynamicClient(&req, util.IssuerURL(svcCtx.Config.Issuer))
+		if err != nil {
+			util.WriteRegistrationError(w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"oauth2-server/internal/logic"
+	"oauth2-server/internal/oautherr"
 	"oauth2-server/internal/svc"
 
 	"github.com/zeromicro/go-zero/rest/httpx"
@@ -14,7 +15,8 @@ func UserInfoHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		l := logic.NewUserInfoLogic(r.Context(), svcCtx)
 		resp, err := l.UserInfo(r)
 		if err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			// 错误按RFC 6750 3.1输出
+			oautherr.WriteBearer(w, err)
 		} else {
 			httpx.OkJsonCtx(r.Context(), w, resp)
 		}
@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ApproveClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewApproveClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveClientLogic {
+	return &ApproveClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ApproveClient 审批通过等待中的客户端，审批后客户端才能用于授权和签发令牌
+func (l *ApproveClientLogic) ApproveClient(req *types.ClientReq) (resp *types.ClientInfo, err error) {
+	client, err := l.svcCtx.ClientModel.FindOne(l.ctx, req.ID)
+	if err == model.ErrNotFound {
+		return nil, errors.New("client not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.IsActive() {
+		client.Status = model.ClientStatusActive
+		if err = l.svcCtx.ClientModel.Update(l.ctx, client); err != nil {
+			return nil, err
+		}
+	}
+
+	info := toClientInfo(client)
+	return &info, nil
+}
@@ -3,16 +3,31 @@ package logic
 import (
 	"context"
 	"errors"
-	"log"
 	"oauth2-server/internal/model"
+	"oauth2-server/internal/oautherr"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
 	"oauth2-server/internal/util"
 	"time"
 
+	"github.com/go-oauth2/oauth2/v4"
+	oauth2errors "github.com/go-oauth2/oauth2/v4/errors"
+	"github.com/google/uuid"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+var (
+	// ErrLoginRequired 用户尚未登录，需要跳转到登录页面
+	ErrLoginRequired = errors.New("login_required")
+	// ErrConsentRequired 用户尚未确认授权，需要跳转到授权页面
+	ErrConsentRequired = errors.New("consent_required")
+
+	// supportedResponseTypes 授权端点支持的响应类型
+	supportedResponseTypes = []string{"code"}
+	// supportedCodeChallengeMethods 支持的PKCE挑战方法
+	supportedCodeChallengeMethods = []string{string(oauth2.CodeChallengeS256), string(oauth2.CodeChallengePlain)}
+)
+
 type AuthorizeLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -27,74 +42,183 @@ func NewAuthorizeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Authori
 	}
 }
 
-func (l *AuthorizeLogic) Authorize(req *types.AuthorizeReq) (resp *types.AuthorizeResp, err error) {
+// Authorize 处理授权请求，userID为会话中已登录的用户，authTime为其登录时间（Unix秒），action为用户在授权页面的选择，尚未选择时为空。
+// 用户未登录时返回ErrLoginRequired，需要用户确认授权时返回ErrConsentRequired。
+// 客户端或回调地址无效时返回错误，由调用方直接展示；验证通过后的错误按RFC 6749 4.1.2.1放在响应中重定向回客户端
+func (l *AuthorizeLogic) Authorize(req *types.AuthorizeReq, userID string, authTime int64, action string) (resp *types.AuthorizeResp, err error) {
 	// 验证客户端
 	client, err := l.svcCtx.ClientModel.FindByID(l.ctx, req.ClientID)
+	if err != nil || !client.IsActive() {
+		return nil, oautherr.New(oautherr.InvalidClient, "unknown client")
+	}
+
+	// 验证重定向URI，必须与注册的地址完全一致（回环地址允许任意端口）
+	redirectURI, err := util.ResolveRedirectURI(client.RedirectURIs(), req.RedirectURI)
 	if err != nil {
-		return nil, errors.New("invalid client")
+		return nil, oautherr.New(oautherr.InvalidRequest, "invalid redirect_uri")
 	}
-	log.Println(client)
 
-	// 验证重定向URI
-	if client.RedirectURL != req.RedirectURI {
-		return nil, errors.New("invalid redirect uri")
+	resp, err = l.authorize(req, client, redirectURI, userID, authTime, action)
+	if err != nil && !errors.Is(err, ErrLoginRequired) && !errors.Is(err, ErrConsentRequired) {
+		return l.errorResponse(req, redirectURI, err), nil
 	}
+	return resp, err
+}
 
+// authorize 在客户端和回调地址验证通过后处理授权请求
+func (l *AuthorizeLogic) authorize(req *types.AuthorizeReq, client *model.Client, redirectURI, userID string, authTime int64, action string) (*types.AuthorizeResp, error) {
 	// 验证响应类型
-	if req.ResponseType != "code" {
-		return nil, errors.New("unsupported response type")
+	if req.ResponseType == "" {
+		return nil, oautherr.New(oautherr.InvalidRequest, "missing response_type")
+	}
+	if !contains(supportedResponseTypes, req.ResponseType) {
+		return nil, oautherr.New(oautherr.UnsupportedResponseType, "")
+	}
+	if !client.AllowsResponseType(req.ResponseType) {
+		return nil, util.ErrUnauthorizedClient
+	}
+
+	// 验证权限范围，只能请求客户端注册的权限，未指定时使用注册的全部权限
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+	requested := req.Scope
+	if req.Scope, err = scopes.Validate(req.Scope, client.Scope); err != nil {
+		return nil, err
 	}
 
-	// 检查是否为自动批准的客户端
-	isAutoApprove := false
-	for _, autoClientID := range l.svcCtx.Config.AutoApproveClients {
-		if autoClientID == req.ClientID {
-			isAutoApprove = true
-			break
+	// 未启用OpenID Connect时拒绝显式请求的openid权限，使用注册的全部权限时去掉openid
+	if !util.OpenIDEnabled(l.svcCtx.Config.Issuer, l.svcCtx.KeySet) {
+		if scopes.Contains(requested, "openid") {
+			return nil, oautherr.New(oautherr.InvalidScope, "openid is not supported")
 		}
+		req.Scope = util.WithoutScope(req.Scope, "openid")
 	}
 
-	// 如果是自动批准的客户端，直接生成授权码
-	if isAutoApprove {
-		return l.generateAuthorizationCode(req, client, "test_user") // 使用默认用户
+	// 验证PKCE参数
+	if err := validateCodeChallenge(req, client); err != nil {
+		return nil, err
 	}
 
-	// 否则需要用户登录和授权
-	// 这里应该重定向到登录页面，暂时返回错误
-	return nil, errors.New("user authorization required")
-}
+	if userID == "" {
+		return nil, ErrLoginRequired
+	}
 
-func (l *AuthorizeLogic) generateAuthorizationCode(req *types.AuthorizeReq, client *model.Client, userID string) (*types.AuthorizeResp, error) {
-	// 创建授权记录
-	auth := &model.Authorization{
-		ClientID: req.ClientID,
-		UserID:   userID,
-		Scope:    req.Scope,
-		Status:   "approved",
+	switch action {
+	case util.ConsentApprove:
+		if err := l.recordDecision(req, userID, model.AuthorizationStatusApproved); err != nil {
+			return nil, err
+		}
+		return l.generateAuthorizationCode(req, redirectURI, userID, authTime)
+	case util.ConsentReject:
+		return l.rejectAuthorization(req, redirectURI, userID)
 	}
 
-	// 插入数据库
-	_, err := l.svcCtx.AuthorizationModel.Insert(l.ctx, auth)
+	// 自动批准的客户端，或有效期内已批准过请求的全部权限，直接生成授权码。
+	// 这两种情况不记录新的授权，记住授权的有效期从用户明确批准时开始计算
+	if contains(l.svcCtx.Config.AutoApproveClients, req.ClientID) {
+		return l.generateAuthorizationCode(req, redirectURI, userID, authTime)
+	}
+	granted, err := util.HasConsent(l.ctx, l.svcCtx.AuthorizationModel, req.ClientID, userID, req.Scope, l.svcCtx.Config.ConsentExpire)
 	if err != nil {
 		return nil, err
 	}
+	if granted {
+		return l.generateAuthorizationCode(req, redirectURI, userID, authTime)
+	}
+
+	return nil, ErrConsentRequired
+}
 
-	// 存储授权码到Redis
+// recordDecision 记录用户在授权页面的选择
+func (l *AuthorizeLogic) recordDecision(req *types.AuthorizeReq, userID, status string) error {
+	_, err := l.svcCtx.AuthorizationModel.Insert(l.ctx, &model.Authorization{
+		ClientID: req.ClientID,
+		UserID:   userID,
+		Scope:    req.Scope,
+		Status:   status,
+	})
+	return err
+}
+
+// generateAuthorizationCode 生成授权码，auth_time记录用户登录的时间而不是签发授权码的时间
+func (l *AuthorizeLogic) generateAuthorizationCode(req *types.AuthorizeReq, redirectURI, userID string, authTime int64) (*types.AuthorizeResp, error) {
+	code := uuid.New().String()
+
+	// 存储授权码到Redis，redirect_uri保存请求中的原值，兑换令牌时需要一致
 	redisStore := util.NewRedisStore(l.svcCtx.Redis)
 	codeData := map[string]interface{}{
 		"client_id":    req.ClientID,
 		"user_id":      userID,
 		"scope":        req.Scope,
 		"redirect_uri": req.RedirectURI,
+		// PKCE挑战码，兑换令牌时校验code_verifier
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+		// OpenID Connect参数，签发ID Token时使用
+		"nonce":     req.Nonce,
+		"auth_time": authTime,
 	}
 
-	err = redisStore.StoreCode(l.ctx, auth.Code, codeData, 10*time.Minute)
+	err := redisStore.StoreCode(l.ctx, code, codeData, 10*time.Minute)
 	if err != nil {
 		return nil, err
 	}
 
 	return &types.AuthorizeResp{
-		Code:  auth.Code,
-		State: req.State,
+		RedirectURI: redirectURI,
+		Code:        code,
+		State:       req.State,
 	}, nil
 }
+
+// rejectAuthorization 记录用户拒绝授权，重定向回客户端并携带access_denied错误
+func (l *AuthorizeLogic) rejectAuthorization(req *types.AuthorizeReq, redirectURI, userID string) (*types.AuthorizeResp, error) {
+	if err := l.recordDecision(req, userID, model.AuthorizationStatusRejected); err != nil {
+		return nil, err
+	}
+
+	return &types.AuthorizeResp{
+		RedirectURI: redirectURI,
+		Error:       oauth2errors.ErrAccessDenied.Error(),
+		State:       req.State,
+	}, nil
+}
+
+// errorResponse 将错误转换为重定向回客户端的错误响应，内部错误不向客户端透露细节
+func (l *AuthorizeLogic) errorResponse(req *types.AuthorizeReq, redirectURI string, err error) *types.AuthorizeResp {
+	e := oautherr.From(err)
+	if e.Code == oautherr.ServerError {
+		l.Errorf("authorize failed: %v", err)
+	}
+	return &types.AuthorizeResp{
+		RedirectURI:      redirectURI,
+		Error:            e.Code,
+		ErrorDescription: e.Description,
+		State:            req.State,
+	}
+}
+
+// validateCodeChallenge 校验PKCE挑战码（RFC 7636），未指定方法时默认为plain
+func validateCodeChallenge(req *types.AuthorizeReq, client *model.Client) error {
+	if req.CodeChallenge == "" {
+		if client.RequirePKCE {
+			return oautherr.New(oautherr.InvalidRequest, "code_challenge required")
+		}
+		return nil
+	}
+
+	if len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return oautherr.New(oautherr.InvalidRequest, "code_challenge must be 43 to 128 characters")
+	}
+
+	if req.CodeChallengeMethod == "" {
+		req.CodeChallengeMethod = string(oauth2.CodeChallengePlain)
+	}
+	if !contains(supportedCodeChallengeMethods, req.CodeChallengeMethod) {
+		return oautherr.New(oautherr.InvalidRequest, "unsupported code_challenge_method")
+	}
+
+	return nil
+}
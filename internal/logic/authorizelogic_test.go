@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/types"
+)
+
+func TestValidateCodeChallenge(t *testing.T) {
+	tests := []struct {
+		name        string
+		requirePKCE bool
+		challenge   string
+		method      string
+		wantMethod  string
+		wantErr     bool
+	}{
+		{"未使用PKCE", false, "", "", "", false},
+		{"强制PKCE时缺少挑战码", true, "", "", "", true},
+		{"S256", true, testCodeChallenge, "S256", "S256", false},
+		{"未指定方法默认为plain", false, testCodeVerifier, "", "plain", false},
+		{"不支持的方法", false, testCodeChallenge, "S512", "", true},
+		{"挑战码过短", false, testCodeChallenge[:42], "S256", "", true},
+		{"挑战码过长", false, strings.Repeat("a", 129), "plain", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &types.AuthorizeReq{CodeChallenge: tt.challenge, CodeChallengeMethod: tt.method}
+			err := validateCodeChallenge(req, &model.Client{RequirePKCE: tt.requirePKCE})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateCodeChallenge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && req.CodeChallengeMethod != tt.wantMethod {
+				t.Errorf("CodeChallengeMethod = %q, want %q", req.CodeChallengeMethod, tt.wantMethod)
+			}
+		})
+	}
+}
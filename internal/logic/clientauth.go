@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/oautherr"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/util"
+)
+
+// authenticateClient 校验客户端ID和密钥
+func authenticateClient(ctx context.Context, svcCtx *svc.ServiceContext, clientID, clientSecret string) (*model.Client, error) {
+	client, err := svcCtx.ClientModel.FindByID(ctx, clientID)
+	if err != nil || !client.IsActive() {
+		return nil, oautherr.New(oautherr.InvalidClient, "unknown client")
+	}
+
+	// 公开客户端没有密钥，只校验客户端ID
+	if client.IsPublic() {
+		return client, nil
+	}
+
+	if !util.VerifyClientSecret(client.Secret, clientSecret) {
+		return nil, oautherr.New(oautherr.InvalidClient, "client authentication failed")
+	}
+
+	return client, nil
+}
@@ -5,6 +5,7 @@ import (
 	"oauth2-server/internal/model"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -24,15 +25,58 @@ func NewClientRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Cl
 }
 
 func (l *ClientRegisterLogic) ClientRegister(req *types.ClientRegisterReq) (resp *types.ClientRegisterResp, err error) {
+	// 注册需要admin访问令牌或初始访问令牌
+	registrant, err := util.AuthenticateRegistrant(l.ctx, l.svcCtx.InitialTokenModel, l.svcCtx.AdminVerifier,
+		req.Authorization, l.svcCtx.Config.Registration.RequireApproval)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验回调地址
+	redirectURIs, err := util.NormalizeRedirectURIs(req.RedirectURL, req.RedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验权限范围均已注册
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = scopes.Validate(req.Scope, req.Scope); err != nil {
+		return nil, err
+	}
+	if !registrant.AllowsScope(scopes, req.Scope) {
+		return nil, util.ErrInvalidScope
+	}
+
+	// 校验授权模式均为令牌端点支持的模式
+	grantTypes, err := util.NormalizeGrantTypes(req.GrantType, supportedGrantTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成客户端密钥，数据库只保存哈希
+	secret, hash, err := util.NewClientSecret()
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建客户端记录
 	client := &model.Client{
+		Secret:      hash,
 		Name:        req.Name,
-		RedirectURL: req.RedirectURL,
-		GrantType:   req.GrantType,
 		Scope:       req.Scope,
+		RequirePKCE: req.RequirePKCE,
+		Status:      registrant.ClientStatus(),
 	}
+	client.SetRedirectURIs(redirectURIs)
+	client.SetGrantTypes(grantTypes)
 
 	// 插入数据库
+	if err = registrant.Consume(l.ctx); err != nil {
+		return nil, err
+	}
 	_, err = l.svcCtx.ClientModel.Insert(l.ctx, client)
 	if err != nil {
 		return nil, err
@@ -40,6 +84,7 @@ func (l *ClientRegisterLogic) ClientRegister(req *types.ClientRegisterReq) (resp
 
 	return &types.ClientRegisterResp{
 		ClientID:     client.ID,
-		ClientSecret: client.Secret,
+		ClientSecret: secret,
+		Status:       client.Status,
 	}, nil
 }
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/oautherr"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ConsentLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewConsentLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ConsentLogic {
+	return &ConsentLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Consent 根据保存的授权请求生成授权页面数据，表单令牌由调用方填写
+func (l *ConsentLogic) Consent(req *types.AuthorizeReq) (resp *util.ConsentPage, err error) {
+	client, err := l.svcCtx.ClientModel.FindByID(l.ctx, req.ClientID)
+	if err != nil || !client.IsActive() {
+		return nil, oautherr.New(oautherr.InvalidClient, "unknown client")
+	}
+
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &util.ConsentPage{
+		ClientName: client.Name,
+		Scopes:     scopes.Describe(req.Scope),
+	}, nil
+}
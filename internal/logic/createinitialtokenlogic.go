@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateInitialTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateInitialTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateInitialTokenLogic {
+	return &CreateInitialTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateInitialToken 创建客户端注册使用的初始访问令牌，明文只返回一次
+func (l *CreateInitialTokenLogic) CreateInitialToken(req *types.InitialAccessTokenCreateReq) (resp *types.InitialAccessTokenCreateResp, err error) {
+	t, token, err := util.NewInitialAccessToken(l.ctx, l.svcCtx.InitialTokenModel, req.Description, req.ExpiresIn, req.MaxUses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InitialAccessTokenCreateResp{
+		Token:     token,
+		ID:        t.ID,
+		ExpiresAt: expiresAt(t),
+		MaxUses:   t.MaxUses,
+	}, nil
+}
+
+// toInitialTokenInfo 转换为接口返回的令牌信息，不包含令牌明文
+func toInitialTokenInfo(t *model.InitialAccessToken) types.InitialAccessTokenInfo {
+	return types.InitialAccessTokenInfo{
+		ID:          t.ID,
+		Description: t.Description,
+		ExpiresAt:   expiresAt(t),
+		MaxUses:     t.MaxUses,
+		Uses:        t.Uses,
+		CreatedAt:   t.CreatedAt.Unix(),
+	}
+}
+
+// expiresAt 返回令牌的过期时间戳，永不过期时为0
+func expiresAt(t *model.InitialAccessToken) int64 {
+	if !t.ExpiresAt.Valid {
+		return 0
+	}
+	return t.ExpiresAt.Time.Unix()
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteClientLogic {
+	return &DeleteClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteClient 删除客户端，同时删除其授权记录并吊销已签发的令牌
+func (l *DeleteClientLogic) DeleteClient(req *types.ClientReq) error {
+	_, err := l.svcCtx.ClientModel.FindOne(l.ctx, req.ID)
+	if err == model.ErrNotFound {
+		return errors.New("client not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	return util.DeleteClient(l.ctx, l.svcCtx.ClientModel, l.svcCtx.AuthorizationModel,
+		util.NewRedisStore(l.svcCtx.Redis), req.ID)
+}
@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteDynamicClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteDynamicClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteDynamicClientLogic {
+	return &DeleteDynamicClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteDynamicClient 按RFC 7592删除客户端，同时删除其授权记录并吊销已签发的令牌
+func (l *DeleteDynamicClientLogic) DeleteDynamicClient(req *types.DynamicClientReq) error {
+	client, err := util.FindRegisteredClient(l.ctx, l.svcCtx.ClientModel, req.ClientID, req.Authorization)
+	if err != nil {
+		return err
+	}
+
+	redisStore := util.NewRedisStore(l.svcCtx.Redis)
+	return util.DeleteClient(l.ctx, l.svcCtx.ClientModel, l.svcCtx.AuthorizationModel, redisStore, client.ID)
+}
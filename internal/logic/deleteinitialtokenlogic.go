@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteInitialTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteInitialTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteInitialTokenLogic {
+	return &DeleteInitialTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteInitialToken 吊销初始访问令牌，已注册的客户端不受影响
+func (l *DeleteInitialTokenLogic) DeleteInitialToken(req *types.InitialAccessTokenReq) error {
+	return l.svcCtx.InitialTokenModel.Delete(l.ctx, req.ID)
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest"
+)
+
+type DiscoveryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDiscoveryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DiscoveryLogic {
+	return &DiscoveryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Discovery 根据已注册的路由和配置生成授权服务器元数据
+func (l *DiscoveryLogic) Discovery(issuer string, routes []rest.Route) (resp *util.ServerMetadata, err error) {
+	// 权限范围取自权限注册表，与授权时的校验保持一致
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.NewServerMetadata(util.MetadataOptions{
+		Issuer:               issuer,
+		Routes:               routes,
+		GrantTypes:           supportedGrantTypes,
+		ResponseTypes:        supportedResponseTypes,
+		Scopes:               scopes.Names(),
+		CodeChallengeMethods: supportedCodeChallengeMethods,
+		Keys:                 l.svcCtx.KeySet.PublishedKeys(),
+	}), nil
+}
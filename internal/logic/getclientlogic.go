@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetClientLogic {
+	return &GetClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetClientLogic) GetClient(req *types.ClientReq) (resp *types.ClientInfo, err error) {
+	client, err := l.svcCtx.ClientModel.FindOne(l.ctx, req.ID)
+	if err == model.ErrNotFound {
+		return nil, errors.New("client not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := toClientInfo(client)
+	return &info, nil
+}
+
+// toClientInfo 转换为接口返回的客户端信息，不包含密钥
+func toClientInfo(client *model.Client) types.ClientInfo {
+	return types.ClientInfo{
+		ClientID:      client.ID,
+		Name:          client.Name,
+		RedirectURIs:  client.RedirectURIs(),
+		GrantType:     client.GrantType,
+		ResponseTypes: client.ResponseTypes(),
+		Scope:         client.Scope,
+		RequirePKCE:   client.RequirePKCE,
+		Status:        client.Status,
+		CreatedAt:     client.CreatedAt.Unix(),
+		UpdatedAt:     client.UpdatedAt.Unix(),
+	}
+}
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetDynamicClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetDynamicClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetDynamicClientLogic {
+	return &GetDynamicClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetDynamicClient 按RFC 7592读取客户端配置，不返回密钥
+func (l *GetDynamicClientLogic) GetDynamicClient(req *types.DynamicClientReq, issuer string) (resp *util.ClientRegistration, err error) {
+	client, err := util.FindRegisteredClient(l.ctx, l.svcCtx.ClientModel, req.ClientID, req.Authorization)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewClientRegistration(client, issuer), nil
+}
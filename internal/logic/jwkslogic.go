@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type JwksLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewJwksLogic(ctx context.Context, svcCtx *svc.ServiceContext) *JwksLogic {
+	return &JwksLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Jwks 返回用于验证访问令牌的公钥集合，包括即将启用和仍在重叠期内的密钥
+func (l *JwksLogic) Jwks() (resp *util.JSONWebKeySet, err error) {
+	return util.NewJSONWebKeySet(l.svcCtx.KeySet.PublishedKeys()...), nil
+}
@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListClientsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListClientsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListClientsLogic {
+	return &ListClientsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListClientsLogic) ListClients(req *types.ClientListReq) (resp *types.ClientListResp, err error) {
+	total, err := l.svcCtx.ClientModel.Count(l.ctx, req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := l.svcCtx.ClientModel.FindPage(l.ctx, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.ClientListResp{
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Clients:  make([]types.ClientInfo, 0, len(clients)),
+	}
+	for _, client := range clients {
+		resp.Clients = append(resp.Clients, toClientInfo(client))
+	}
+	return resp, nil
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListGrantsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListGrantsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListGrantsLogic {
+	return &ListGrantsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListGrants 列出用户已授权的应用，与go-oauth2服务共用授权记录
+func (l *ListGrantsLogic) ListGrants(userID string) (resp []*util.Grant, err error) {
+	return util.ListGrants(l.ctx, l.svcCtx.AuthorizationModel, l.svcCtx.ClientModel, userID)
+}
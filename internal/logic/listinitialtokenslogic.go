@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListInitialTokensLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListInitialTokensLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListInitialTokensLogic {
+	return &ListInitialTokensLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListInitialTokens 查询全部初始访问令牌，包括已过期和已用完的
+func (l *ListInitialTokensLogic) ListInitialTokens() (resp *types.InitialAccessTokenListResp, err error) {
+	tokens, err := l.svcCtx.InitialTokenModel.FindAll(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.InitialAccessTokenListResp{Tokens: make([]types.InitialAccessTokenInfo, 0, len(tokens))}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, toInitialTokenInfo(t))
+	}
+	return resp, nil
+}
@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type LoginLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic {
+	return &LoginLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Login 校验用户名和密码，用户名或密码错误时返回util.ErrInvalidCredentials
+func (l *LoginLogic) Login(req *types.LoginReq) (resp *types.LoginResp, err error) {
+	user, err := util.AuthenticateUser(l.ctx, l.svcCtx.UserModel, req.Username, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.LoginResp{UserID: user.ID}, nil
+}
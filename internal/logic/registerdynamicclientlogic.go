@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RegisterDynamicClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRegisterDynamicClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegisterDynamicClientLogic {
+	return &RegisterDynamicClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RegisterDynamicClient 按RFC 7591注册客户端，返回密钥和注册访问令牌
+func (l *RegisterDynamicClientLogic) RegisterDynamicClient(req *types.DynamicClientRegisterReq, issuer string) (resp *util.ClientRegistration, err error) {
+	registrant, err := util.AuthenticateRegistrant(l.ctx, l.svcCtx.InitialTokenModel, l.svcCtx.AdminVerifier,
+		req.Authorization, l.svcCtx.Config.Registration.RequireApproval)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &util.ClientMetadata{
+		RedirectURIs:            req.RedirectURIs,
+		GrantTypes:              req.GrantTypes,
+		ResponseTypes:           req.ResponseTypes,
+		TokenEndpointAuthMethod: req.TokenEndpointAuthMethod,
+		ClientName:              req.ClientName,
+		LogoURI:                 req.LogoURI,
+		JWKS:                    req.JWKS,
+		Scope:                   req.Scope,
+	}
+	return util.RegisterClient(l.ctx, l.svcCtx.ClientModel, l.svcCtx.ScopeModel, registrant, md, supportedGrantTypes, issuer)
+}
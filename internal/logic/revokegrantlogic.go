@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RevokeGrantLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeGrantLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeGrantLogic {
+	return &RevokeGrantLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RevokeGrant 撤销用户对客户端的授权，并吊销已签发给该客户端的令牌
+func (l *RevokeGrantLogic) RevokeGrant(userID string, req *types.GrantReq) error {
+	redisStore := util.NewRedisStore(l.svcCtx.Redis)
+	return util.RevokeGrant(l.ctx, l.svcCtx.AuthorizationModel, redisStore, userID, req.ClientID)
+}
@@ -3,16 +3,22 @@ package logic
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/oautherr"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
 	"oauth2-server/internal/util"
+	"strings"
 	"time"
 
+	"github.com/go-oauth2/oauth2/v4"
 	"github.com/google/uuid"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// supportedGrantTypes 令牌端点支持的授权类型
+var supportedGrantTypes = []string{"authorization_code", "refresh_token", "client_credentials"}
+
 type TokenLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -29,90 +35,383 @@ func NewTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TokenLogic
 
 func (l *TokenLogic) Token(req *types.TokenReq) (resp *types.TokenResp, err error) {
 	// 验证授权类型
-	if req.GrantType != "authorization_code" {
-		return nil, errors.New("unsupported grant type")
+	if !contains(supportedGrantTypes, req.GrantType) {
+		return nil, oautherr.New(oautherr.UnsupportedGrantType, "")
 	}
 
 	// 验证客户端
-	client, err := l.svcCtx.ClientModel.FindByID(l.ctx, req.ClientID)
+	client, err := authenticateClient(l.ctx, l.svcCtx, req.ClientID, req.ClientSecret)
 	if err != nil {
-		return nil, errors.New("invalid client")
+		return nil, err
 	}
 
-	// 验证客户端密钥
-	if client.Secret != req.ClientSecret {
-		return nil, errors.New("invalid client secret")
+	// 客户端只能使用注册时允许的授权模式
+	if !client.AllowsGrantType(req.GrantType) {
+		return nil, util.ErrUnauthorizedClient
+	}
+
+	switch req.GrantType {
+	case "refresh_token":
+		return l.refreshToken(req)
+	case "client_credentials":
+		return l.clientCredentials(req, client)
 	}
 
 	// 从Redis获取授权码数据
 	redisStore := util.NewRedisStore(l.svcCtx.Redis)
 	codeDataStr, err := redisStore.GetCode(l.ctx, req.Code)
 	if err != nil {
-		return nil, errors.New("invalid authorization code")
+		return nil, err
+	}
+	if codeDataStr == "" {
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid authorization code")
 	}
 
 	// 解析授权码数据
 	var codeData map[string]interface{}
 	err = json.Unmarshal([]byte(codeDataStr), &codeData)
 	if err != nil {
-		return nil, errors.New("invalid authorization code data")
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid authorization code")
 	}
 
 	// 验证客户端ID
 	if codeData["client_id"] != req.ClientID {
-		return nil, errors.New("client_id mismatch")
+		return nil, oautherr.New(oautherr.InvalidGrant, "authorization code was issued to another client")
 	}
 
 	// 验证重定向URI
 	if codeData["redirect_uri"] != req.RedirectURI {
-		return nil, errors.New("redirect_uri mismatch")
+		return nil, oautherr.New(oautherr.InvalidGrant, "redirect_uri mismatch")
 	}
 
-	// 生成访问令牌
-	accessToken, err := util.GenerateToken(
-		codeData["user_id"].(string),
-		req.ClientID,
-		codeData["scope"].(string),
-		l.svcCtx.Config.Auth.AccessSecret,
-		l.svcCtx.Config.Auth.AccessExpire,
-	)
+	// 验证PKCE校验码
+	if err := verifyCodeVerifier(codeData, req.CodeVerifier); err != nil {
+		return nil, err
+	}
+
+	userID, ok := codeData["user_id"].(string)
+	if !ok {
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid authorization code")
+	}
+	scope, ok := codeData["scope"].(string)
+	if !ok {
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid authorization code")
+	}
+
+	// 每次授权码兑换开启一个新的令牌族
+	authTime := int64Value(codeData["auth_time"])
+	resp, err = l.issueToken(userID, req.ClientID, scope, uuid.New().String(), authTime)
 	if err != nil {
 		return nil, err
 	}
 
-	// 生成刷新令牌
-	refreshToken := uuid.New().String()
+	// 请求了openid权限时签发ID Token
+	if l.openID(scope) {
+		nonce, _ := codeData["nonce"].(string)
+		resp.IDToken, err = l.issueIDToken(userID, req.ClientID, nonce, authTime)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// 存储访问令牌到Redis
-	tokenData := map[string]interface{}{
-		"user_id":   codeData["user_id"],
-		"client_id": req.ClientID,
-		"scope":     codeData["scope"],
+	// 删除授权码
+	redisStore.DeleteCode(l.ctx, req.Code)
+
+	return resp, nil
+}
+
+// refreshToken 使用刷新令牌换取新的访问令牌，并轮换刷新令牌
+func (l *TokenLogic) refreshToken(req *types.TokenReq) (*types.TokenResp, error) {
+	if req.RefreshToken == "" {
+		return nil, oautherr.New(oautherr.InvalidRequest, "missing refresh_token")
 	}
-	err = redisStore.StoreAccessToken(l.ctx, accessToken, tokenData, time.Duration(l.svcCtx.Config.Auth.AccessExpire)*time.Second)
+
+	// 从Redis获取刷新令牌数据
+	redisStore := util.NewRedisStore(l.svcCtx.Redis)
+	refreshDataStr, err := redisStore.GetRefreshToken(l.ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if refreshDataStr == "" {
+		// 轮换后旧刷新令牌即被删除，再次出现说明可能被盗用，吊销整个令牌族
+		return nil, l.detectRefreshTokenReuse(redisStore, req.RefreshToken)
+	}
+
+	// 解析刷新令牌数据
+	var refreshData map[string]interface{}
+	err = json.Unmarshal([]byte(refreshDataStr), &refreshData)
+	if err != nil {
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid refresh token")
+	}
+
+	// 验证客户端ID
+	if refreshData["client_id"] != req.ClientID {
+		return nil, oautherr.New(oautherr.InvalidGrant, "refresh token was issued to another client")
+	}
+
+	// 缺少用户或权限范围的数据无法用于刷新
+	userID, ok := refreshData["user_id"].(string)
+	if !ok {
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid refresh token")
+	}
+	grantedScope, ok := refreshData["scope"].(string)
+	if !ok {
+		return nil, oautherr.New(oautherr.InvalidGrant, "invalid refresh token")
+	}
+
+	// 新的权限范围只能缩小，不能扩大。校验失败时不消耗刷新令牌，客户端修正后可以重试
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := scopes.Validate(req.Scope, grantedScope)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, _ := refreshData["family_id"].(string)
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
+
+	// 签发前才标记旧刷新令牌已使用，并发请求中只有一个能够成功
+	fresh, err := redisStore.MarkRefreshTokenUsed(l.ctx, req.RefreshToken, familyID, l.refreshExpire())
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, l.detectRefreshTokenReuse(redisStore, req.RefreshToken)
+	}
+
+	authTime := int64Value(refreshData["auth_time"])
+	resp, err := l.issueRefreshedToken(userID, req.ClientID, scope, familyID, authTime)
+	if err != nil {
+		// 签发失败时撤销标记，按server_error响应，客户端重试不会被误判为重放
+		if err := redisStore.ReleaseRefreshToken(l.ctx, req.RefreshToken); err != nil {
+			l.Errorf("release refresh token failed: %v", err)
+		}
+		return nil, err
+	}
+
+	// 轮换成功后删除旧刷新令牌，两个服务都只能通过已使用标记识别它
+	if err := redisStore.DeleteRefreshToken(l.ctx, req.RefreshToken); err != nil {
+		l.Errorf("delete rotated refresh token failed: %v", err)
+	}
+
+	return resp, nil
+}
+
+// detectRefreshTokenReuse 已轮换过的刷新令牌再次出现说明可能被盗用，吊销其所属的整个令牌族
+func (l *TokenLogic) detectRefreshTokenReuse(redisStore *util.RedisStore, refreshToken string) error {
+	familyID, err := redisStore.UsedRefreshTokenFamily(l.ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if familyID == "" {
+		return oautherr.New(oautherr.InvalidGrant, "invalid refresh token")
+	}
+
+	if err := redisStore.RevokeFamily(l.ctx, familyID); err != nil {
+		l.Errorf("revoke token family %s failed: %v", familyID, err)
+	}
+	return oautherr.New(oautherr.InvalidGrant, "refresh token reuse detected")
+}
+
+// issueRefreshedToken 刷新时签发新的令牌，同样签发ID Token，但不再携带nonce
+func (l *TokenLogic) issueRefreshedToken(userID, clientID, scope, familyID string, authTime int64) (*types.TokenResp, error) {
+	resp, err := l.issueToken(userID, clientID, scope, familyID, authTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.openID(scope) {
+		resp.IDToken, err = l.issueIDToken(userID, clientID, "", authTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// clientCredentials 客户端凭证模式，令牌不关联用户且不颁发刷新令牌
+func (l *TokenLogic) clientCredentials(req *types.TokenReq, client *model.Client) (*types.TokenResp, error) {
+	// 权限范围只能是客户端注册范围的子集，未指定时使用注册的全部范围
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := scopes.Validate(req.Scope, client.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID := uuid.New().String()
+	accessToken, err := l.issueAccessToken("", req.ClientID, scope, familyID)
 	if err != nil {
 		return nil, err
 	}
 
+	// 客户端自身持有的令牌同样记录下来，删除客户端时一并吊销
+	redisStore := util.NewRedisStore(l.svcCtx.Redis)
+	err = redisStore.AddFamilyToGrant(l.ctx, "", req.ClientID, familyID, time.Duration(l.svcCtx.Config.Auth.AccessExpire)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TokenResp{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   l.svcCtx.Config.Auth.AccessExpire,
+		Scope:       scope,
+	}, nil
+}
+
+// issueToken 生成并存储访问令牌和刷新令牌
+func (l *TokenLogic) issueToken(userID, clientID, scope, familyID string, authTime int64) (*types.TokenResp, error) {
+	accessToken, err := l.issueAccessToken(userID, clientID, scope, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成刷新令牌
+	refreshToken := uuid.New().String()
+
 	// 存储刷新令牌到Redis
+	redisStore := util.NewRedisStore(l.svcCtx.Redis)
+	refreshExpire := l.refreshExpire()
 	refreshTokenData := map[string]interface{}{
-		"user_id":   codeData["user_id"],
-		"client_id": req.ClientID,
-		"scope":     codeData["scope"],
+		"user_id":   userID,
+		"client_id": clientID,
+		"scope":     scope,
+		"family_id": familyID,
+		"auth_time": authTime,
 	}
-	err = redisStore.StoreRefreshToken(l.ctx, refreshToken, refreshTokenData, 30*24*time.Hour) // 30天
+	err = redisStore.StoreRefreshToken(l.ctx, refreshToken, refreshTokenData, refreshExpire)
 	if err != nil {
 		return nil, err
 	}
 
-	// 删除授权码
-	redisStore.DeleteCode(l.ctx, req.Code)
+	// 记录令牌族成员，以便重放检测时整体吊销
+	if err = redisStore.AddRefreshTokenToFamily(l.ctx, familyID, refreshToken, refreshExpire); err != nil {
+		return nil, err
+	}
+
+	// 记录用户授权给客户端的令牌族，以便用户撤销授权时一并吊销
+	if err = redisStore.AddFamilyToGrant(l.ctx, userID, clientID, familyID, refreshExpire); err != nil {
+		return nil, err
+	}
 
 	return &types.TokenResp{
 		AccessToken:  accessToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    l.svcCtx.Config.Auth.AccessExpire,
 		RefreshToken: refreshToken,
-		Scope:        codeData["scope"].(string),
+		Scope:        scope,
 	}, nil
 }
+
+// issueAccessToken 生成访问令牌并存储到Redis，familyID不为空时同时记录到令牌族
+func (l *TokenLogic) issueAccessToken(userID, clientID, scope, familyID string) (string, error) {
+	// 生成访问令牌
+	accessToken, err := util.GenerateToken(
+		userID,
+		clientID,
+		scope,
+		l.svcCtx.KeySet,
+		l.svcCtx.Config.Auth.AccessExpire,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// 存储访问令牌到Redis
+	redisStore := util.NewRedisStore(l.svcCtx.Redis)
+	tokenData := map[string]interface{}{
+		"user_id":   userID,
+		"client_id": clientID,
+		"scope":     scope,
+		"family_id": familyID,
+	}
+	err = redisStore.StoreAccessToken(l.ctx, accessToken, tokenData, time.Duration(l.svcCtx.Config.Auth.AccessExpire)*time.Second)
+	if err != nil {
+		return "", err
+	}
+
+	if familyID != "" {
+		if err = redisStore.AddAccessTokenToFamily(l.ctx, familyID, accessToken, l.refreshExpire()); err != nil {
+			return "", err
+		}
+	}
+
+	return accessToken, nil
+}
+
+// openID 是否需要签发ID Token，未启用OpenID Connect时授权端点已拒绝openid权限，这里只处理配置变更前签发的授权
+func (l *TokenLogic) openID(scope string) bool {
+	return hasScope(scope, "openid") && util.OpenIDEnabled(l.svcCtx.Config.Issuer, l.svcCtx.KeySet)
+}
+
+// issueIDToken 签发OpenID Connect ID Token，iss使用配置的Issuer
+func (l *TokenLogic) issueIDToken(userID, clientID, nonce string, authTime int64) (string, error) {
+	return util.GenerateIDToken(
+		util.IssuerURL(l.svcCtx.Config.Issuer),
+		userID,
+		clientID,
+		nonce,
+		time.Unix(authTime, 0),
+		l.svcCtx.KeySet,
+		l.svcCtx.Config.Auth.AccessExpire,
+	)
+}
+
+func (l *TokenLogic) refreshExpire() time.Duration {
+	return time.Duration(l.svcCtx.Config.Auth.RefreshExpire) * time.Second
+}
+
+// verifyCodeVerifier 校验code_verifier与授权时保存的挑战码是否匹配
+func verifyCodeVerifier(codeData map[string]interface{}, verifier string) error {
+	challenge, _ := codeData["code_challenge"].(string)
+	if challenge == "" {
+		if verifier != "" {
+			return oautherr.New(oautherr.InvalidGrant, "code_verifier provided without code_challenge")
+		}
+		return nil
+	}
+
+	if verifier == "" {
+		return oautherr.New(oautherr.InvalidGrant, "missing code_verifier")
+	}
+
+	// 与授权端点一致，未指定方法时按plain校验
+	method, _ := codeData["code_challenge_method"].(string)
+	if method == "" {
+		method = string(oauth2.CodeChallengePlain)
+	}
+	if !oauth2.CodeChallengeMethod(method).Validate(challenge, verifier) {
+		return oautherr.New(oautherr.InvalidGrant, "invalid code_verifier")
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hasScope 判断以空格分隔的scope中是否包含指定权限
+func hasScope(scope, name string) bool {
+	return contains(strings.Fields(scope), name)
+}
+
+// int64Value 读取JSON反序列化后的数值字段
+func int64Value(v interface{}) int64 {
+	if f, ok := v.(float64); ok {
+		return int64(f)
+	}
+	return 0
+}
@@ -0,0 +1,253 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/oautherr"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+)
+
+// RFC 7636 附录B中的示例
+const (
+	testCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestVerifyCodeVerifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		method    string
+		verifier  string
+		wantErr   bool
+	}{
+		{"未使用PKCE", "", "", "", false},
+		{"未使用PKCE却提交了code_verifier", "", "", testCodeVerifier, true},
+		{"S256匹配", testCodeChallenge, "S256", testCodeVerifier, false},
+		{"S256不匹配", testCodeChallenge, "S256", testCodeVerifier + "x", true},
+		{"S256缺少code_verifier", testCodeChallenge, "S256", "", true},
+		{"S256提交挑战码本身", testCodeChallenge, "S256", testCodeChallenge, true},
+		{"plain匹配", testCodeVerifier, "plain", testCodeVerifier, false},
+		{"plain不匹配", testCodeVerifier, "plain", testCodeChallenge, true},
+		{"未指定方法按plain校验", testCodeVerifier, "", testCodeVerifier, false},
+		{"未指定方法时不按S256校验", testCodeChallenge, "", testCodeVerifier, true},
+		{"不支持的方法", testCodeVerifier, "S512", testCodeVerifier, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codeData := map[string]interface{}{
+				"code_challenge":        tt.challenge,
+				"code_challenge_method": tt.method,
+			}
+			err := verifyCodeVerifier(codeData, tt.verifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyCodeVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && oautherr.From(err).Code != oautherr.InvalidGrant {
+				t.Errorf("verifyCodeVerifier() error code = %s, want invalid_grant", oautherr.From(err).Code)
+			}
+		})
+	}
+}
+
+// fakeScopeModel 只实现FindAll，返回内置权限
+type fakeScopeModel struct {
+	model.ScopeModel
+}
+
+func (fakeScopeModel) FindAll(context.Context) ([]*model.Scope, error) {
+	return []*model.Scope{
+		{Name: "userid"},
+		{Name: "profile", Implies: "userid"},
+	}, nil
+}
+
+// newTestTokenLogic 创建使用miniredis和共享密钥签名的TokenLogic
+func newTestTokenLogic(t *testing.T) (*TokenLogic, *util.RedisStore) {
+	rds := redis.New(miniredis.RunT(t).Addr())
+	svcCtx := &svc.ServiceContext{
+		Redis:      *rds,
+		ScopeModel: fakeScopeModel{},
+		KeySet:     util.NewKeySet(time.Hour, util.NewHMACSigningKey("secret")),
+	}
+	svcCtx.Config.Auth.AccessExpire = 3600
+	svcCtx.Config.Auth.RefreshExpire = 86400
+	return NewTokenLogic(context.Background(), svcCtx), util.NewRedisStore(*rds)
+}
+
+func TestRefreshTokenReplayThroughTokenStore(t *testing.T) {
+	l, redisStore := newTestTokenLogic(t)
+	ctx := context.Background()
+
+	first, err := l.issueToken("user", "client", "userid", "family", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// go-oauth2服务读取go-zero服务已轮换的刷新令牌，按重放处理并吊销整个令牌族
+	tokenStore := util.NewTokenStore(redisStore, time.Hour)
+	ti, err := tokenStore.GetByRefresh(ctx, first.RefreshToken)
+	if err != nil || ti != nil {
+		t.Fatalf("GetByRefresh() of rotated token = %v, %v, want nil", ti, err)
+	}
+	if ti, _ := tokenStore.GetByRefresh(ctx, rotated.RefreshToken); ti != nil {
+		t.Errorf("rotated refresh token survived replay")
+	}
+	if ti, _ := tokenStore.GetByAccess(ctx, rotated.AccessToken); ti != nil {
+		t.Errorf("rotated access token survived replay")
+	}
+}
+
+func TestRefreshTokenRotatedByTokenStoreIsReplay(t *testing.T) {
+	l, redisStore := newTestTokenLogic(t)
+	ctx := context.Background()
+
+	first, err := l.issueToken("user", "client", "userid", "family", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// go-oauth2服务轮换后删除旧刷新令牌，go-zero服务再次收到时按重放处理
+	tokenStore := util.NewTokenStore(redisStore, time.Hour)
+	if err := tokenStore.RemoveByRefresh(ctx, first.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken})
+	if e := oautherr.From(err); e.Code != oautherr.InvalidGrant || e.Description != "refresh token reuse detected" {
+		t.Fatalf("refreshToken() error = %v, want reuse detected", err)
+	}
+	if data, _ := redisStore.GetAccessToken(ctx, first.AccessToken); data != "" {
+		t.Errorf("access token survived replay")
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	l, redisStore := newTestTokenLogic(t)
+	ctx := context.Background()
+
+	first, err := l.issueToken("user", "client", "userid profile", "family", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken, Scope: "userid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refreshToken() returned refresh token %q, want a new one", second.RefreshToken)
+	}
+	if second.Scope != "userid" {
+		t.Errorf("refreshToken() scope = %q, want userid", second.Scope)
+	}
+	if data, _ := redisStore.GetRefreshToken(ctx, first.RefreshToken); data != "" {
+		t.Errorf("rotated refresh token was not deleted")
+	}
+
+	// 新的刷新令牌可以继续轮换，且沿用原令牌族
+	third, err := l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: second.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if family, _ := redisStore.UsedRefreshTokenFamily(ctx, second.RefreshToken); family != "family" {
+		t.Errorf("used refresh token family = %q, want family", family)
+	}
+	if third.RefreshToken == second.RefreshToken {
+		t.Errorf("refreshToken() did not rotate the refresh token")
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	l, redisStore := newTestTokenLogic(t)
+	ctx := context.Background()
+
+	first, err := l.issueToken("user", "client", "userid", "family", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken})
+	if e := oautherr.From(err); e.Code != oautherr.InvalidGrant || e.Description != "refresh token reuse detected" {
+		t.Fatalf("refreshToken() replay error = %v, want reuse detected", err)
+	}
+	for _, token := range []string{first.AccessToken, second.AccessToken} {
+		if data, _ := redisStore.GetAccessToken(ctx, token); data != "" {
+			t.Errorf("access token %s survived replay", token)
+		}
+	}
+	if data, _ := redisStore.GetRefreshToken(ctx, second.RefreshToken); data != "" {
+		t.Errorf("current refresh token survived replay")
+	}
+}
+
+func TestRefreshTokenNotConsumedOnFailure(t *testing.T) {
+	l, redisStore := newTestTokenLogic(t)
+	ctx := context.Background()
+
+	first, err := l.issueToken("user", "client", "userid", "family", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 请求超出原有范围的权限
+	_, err = l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken, Scope: "profile"})
+	if e := oautherr.From(err); e.Code != oautherr.InvalidScope {
+		t.Fatalf("refreshToken() error = %v, want invalid_scope", err)
+	}
+
+	// 签发失败时撤销已使用标记，按server_error响应
+	keySet := l.svcCtx.KeySet
+	l.svcCtx.KeySet = util.NewKeySet(time.Hour)
+	_, err = l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken})
+	if e := oautherr.From(err); e.Code != oautherr.ServerError {
+		t.Fatalf("refreshToken() error = %v, want server_error", err)
+	}
+	if family, _ := redisStore.UsedRefreshTokenFamily(ctx, first.RefreshToken); family != "" {
+		t.Errorf("refresh token still marked as used after a failed issue")
+	}
+
+	// 客户端重试不会被误判为重放
+	l.svcCtx.KeySet = keySet
+	if _, err := l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: first.RefreshToken}); err != nil {
+		t.Errorf("refreshToken() retry error = %v", err)
+	}
+}
+
+func TestRefreshTokenWithIncompleteData(t *testing.T) {
+	l, redisStore := newTestTokenLogic(t)
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+	}{
+		{"缺少user_id", map[string]interface{}{"client_id": "client", "scope": "userid"}},
+		{"缺少scope", map[string]interface{}{"client_id": "client", "user_id": "user"}},
+		{"user_id类型错误", map[string]interface{}{"client_id": "client", "user_id": 1, "scope": "userid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := redisStore.StoreRefreshToken(context.Background(), tt.name, tt.data, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			_, err := l.refreshToken(&types.TokenReq{ClientID: "client", RefreshToken: tt.name})
+			if e := oautherr.From(err); e.Code != oautherr.InvalidGrant {
+				t.Errorf("refreshToken() error = %v, want invalid_grant", err)
+			}
+		})
+	}
+}
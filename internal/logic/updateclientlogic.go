@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateClientLogic {
+	return &UpdateClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UpdateClient 更新客户端信息，密钥通过单独的接口重新生成
+func (l *UpdateClientLogic) UpdateClient(req *types.ClientUpdateReq) (resp *types.ClientInfo, err error) {
+	redirectURIs, err := util.NormalizeRedirectURIs(req.RedirectURL, req.RedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验权限范围均已注册
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = scopes.Validate(req.Scope, req.Scope); err != nil {
+		return nil, err
+	}
+
+	// 校验授权模式均为令牌端点支持的模式
+	grantTypes, err := util.NormalizeGrantTypes(req.GrantType, supportedGrantTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := l.svcCtx.ClientModel.FindOne(l.ctx, req.ID)
+	if err == model.ErrNotFound {
+		return nil, errors.New("client not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = util.CheckPublicGrantTypes(client, grantTypes); err != nil {
+		return nil, err
+	}
+
+	client.Name = req.Name
+	client.SetRedirectURIs(redirectURIs)
+	client.SetGrantTypes(grantTypes)
+	client.Scope = req.Scope
+	client.RequirePKCE = req.RequirePKCE
+	if err = l.svcCtx.ClientModel.Update(l.ctx, client); err != nil {
+		return nil, err
+	}
+
+	info := toClientInfo(client)
+	return &info, nil
+}
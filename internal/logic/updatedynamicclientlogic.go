@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"oauth2-server/internal/svc"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateDynamicClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateDynamicClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateDynamicClientLogic {
+	return &UpdateDynamicClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UpdateDynamicClient 按RFC 7592更新客户端配置，密钥和注册访问令牌保持不变
+func (l *UpdateDynamicClientLogic) UpdateDynamicClient(req *types.DynamicClientUpdateReq, issuer string) (resp *util.ClientRegistration, err error) {
+	client, err := util.FindRegisteredClient(l.ctx, l.svcCtx.ClientModel, req.ID, req.Authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	// 请求体必须携带与地址一致的client_id
+	if req.ClientID != client.ID {
+		return nil, &util.RegistrationError{
+			Code:        util.RegistrationErrInvalidClientMetadata,
+			Description: "client_id does not match",
+		}
+	}
+
+	md := &util.ClientMetadata{
+		RedirectURIs:            req.RedirectURIs,
+		GrantTypes:              req.GrantTypes,
+		ResponseTypes:           req.ResponseTypes,
+		TokenEndpointAuthMethod: req.TokenEndpointAuthMethod,
+		ClientName:              req.ClientName,
+		LogoURI:                 req.LogoURI,
+		JWKS:                    req.JWKS,
+		Scope:                   req.Scope,
+	}
+	if err := util.UpdateRegisteredClient(l.ctx, l.svcCtx.ClientModel, l.svcCtx.ScopeModel, client, md, supportedGrantTypes); err != nil {
+		return nil, err
+	}
+	return util.NewClientRegistration(client, issuer), nil
+}
@@ -2,12 +2,12 @@ package logic
 
 import (
 	"context"
-	"errors"
 	"net/http"
+	"oauth2-server/internal/model"
+	"oauth2-server/internal/oautherr"
 	"oauth2-server/internal/svc"
 	"oauth2-server/internal/types"
 	"oauth2-server/internal/util"
-	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -27,45 +27,56 @@ func NewUserInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserInfo
 }
 
 func (l *UserInfoLogic) UserInfo(r *http.Request) (resp *types.UserInfoResp, err error) {
-	// 从请求头获取Authorization
+	// 从请求头获取Authorization，按RFC 6750返回Bearer错误
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return nil, errors.New("missing authorization header")
+		return nil, oautherr.ErrMissingToken
 	}
 
 	// 解析Bearer token
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return nil, errors.New("invalid authorization header")
+	token, ok := util.BearerToken(authHeader)
+	if !ok {
+		return nil, oautherr.New(oautherr.InvalidRequest, "invalid authorization header")
 	}
 
-	token := parts[1]
-
 	// 验证JWT token
-	claims, err := util.ParseToken(token, l.svcCtx.Config.Auth.AccessSecret)
+	claims, err := util.ParseToken(token, l.svcCtx.KeySet)
 	if err != nil {
-		return nil, errors.New("invalid token")
+		return nil, oautherr.New(oautherr.InvalidToken, "invalid access token")
 	}
 
-	// 从Redis验证token是否有效
+	// 从Redis验证token是否有效，令牌不存在时GetAccessToken返回空字符串
 	redisStore := util.NewRedisStore(l.svcCtx.Redis)
-	_, err = redisStore.GetAccessToken(l.ctx, token)
+	data, err := redisStore.GetAccessToken(l.ctx, token)
 	if err != nil {
-		return nil, errors.New("token expired or invalid")
+		return nil, err
+	}
+	if data == "" {
+		return nil, oautherr.New(oautherr.InvalidToken, "access token expired or revoked")
 	}
 
-	// 根据scope返回相应的用户信息
+	// 根据scope（含隐含权限）返回相应的用户信息
+	scopes, err := util.LoadScopes(l.ctx, l.svcCtx.ScopeModel)
+	if err != nil {
+		return nil, err
+	}
 	userInfo := &types.UserInfoResp{}
 
-	// 模拟用户数据，实际应该从数据库获取
-	if strings.Contains(claims.Scope, "userid") {
+	if scopes.Contains(claims.Scope, "userid") {
 		userInfo.UserID = claims.UserID
 	}
 
-	if strings.Contains(claims.Scope, "profile") {
-		// 模拟用户信息
-		userInfo.Username = "test_user"
-		userInfo.Phone = "13800138000"
+	if scopes.Contains(claims.Scope, "profile") {
+		// 从数据库获取用户信息
+		user, err := l.svcCtx.UserModel.FindOne(l.ctx, claims.UserID)
+		if err == model.ErrNotFound {
+			return nil, oautherr.New(oautherr.InvalidToken, "access token is not associated with a user")
+		}
+		if err != nil {
+			return nil, err
+		}
+		userInfo.Username = user.Username
+		userInfo.Phone = user.Phone
 	}
 
 	return userInfo, nil
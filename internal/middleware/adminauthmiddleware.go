@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"oauth2-server/internal/util"
+)
+
+// AdminAuthMiddleware 管理接口要求携带包含admin权限的Bearer访问令牌
+type AdminAuthMiddleware struct {
+	verify util.AdminVerifier
+}
+
+func NewAdminAuthMiddleware(verify util.AdminVerifier) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{verify: verify}
+}
+
+func (m *AdminAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := util.BearerToken(r.Header.Get("Authorization"))
+		if !ok || !m.verify(r.Context(), token) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", scope="`+util.AdminScope+`"`)
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -11,11 +11,19 @@ type Authorization struct {
 	UserID    string    `db:"user_id" json:"user_id"`       // 用户ID
 	Scope     string    `db:"scope" json:"scope"`           // 请求的权限范围
 	Code      string    `db:"code" json:"code"`             // 授权码
-	Status    string    `db:"status" json:"status"`         // 状态：pending/approved/rejected
+	Status    string    `db:"status" json:"status"`         // 状态：pending/approved/rejected/revoked
 	CreatedAt time.Time `db:"created_at" json:"created_at"` // 创建时间
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // 更新时间
 }
 
+// 授权记录状态
+const (
+	AuthorizationStatusPending  = "pending"
+	AuthorizationStatusApproved = "approved"
+	AuthorizationStatusRejected = "rejected"
+	AuthorizationStatusRevoked  = "revoked"
+)
+
 // AuthorizationReq 授权请求
 type AuthorizationReq struct {
 	ClientID     string `json:"client_id"`     // 客户端ID
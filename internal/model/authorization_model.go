@@ -13,6 +13,11 @@ type AuthorizationModel interface {
 	Insert(ctx context.Context, data *Authorization) (sql.Result, error)
 	FindOne(ctx context.Context, id int64) (*Authorization, error)
 	FindByCode(ctx context.Context, code string) (*Authorization, error)
+	FindApproved(ctx context.Context, clientID, userID string, since time.Time) ([]*Authorization, error)
+	FindByUser(ctx context.Context, userID string) ([]*Authorization, error)
+	Revoke(ctx context.Context, clientID, userID string) error
+	FindUserIDsByClient(ctx context.Context, clientID string) ([]string, error)
+	DeleteByClient(ctx context.Context, clientID string) error
 	Update(ctx context.Context, data *Authorization) error
 	Delete(ctx context.Context, id int64) error
 }
@@ -39,7 +44,7 @@ func (m *defaultAuthorizationModel) Insert(ctx context.Context, data *Authorizat
 	data.CreatedAt = now
 	data.UpdatedAt = now
 
-	query := `insert into ` + m.table + ` (` + authorizationRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `insert into ` + m.table + ` (` + authorizationRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?, ?)`
 	return m.conn.ExecCtx(ctx, query, data.ClientID, data.UserID, data.Scope, data.Code, data.Status, data.CreatedAt, data.UpdatedAt)
 }
 
@@ -71,6 +76,53 @@ func (m *defaultAuthorizationModel) FindByCode(ctx context.Context, code string)
 	}
 }
 
+// FindApproved 查询用户在since之后对客户端批准的授权记录
+func (m *defaultAuthorizationModel) FindApproved(ctx context.Context, clientID, userID string, since time.Time) ([]*Authorization, error) {
+	query := `select ` + authorizationRows + ` from ` + m.table + ` where client_id = ? and user_id = ? and status = ? and created_at > ? order by id desc`
+	var resp []*Authorization
+	err := m.conn.QueryRowsCtx(ctx, &resp, query, clientID, userID, AuthorizationStatusApproved, since)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FindByUser 按授权时间顺序查询用户的全部授权记录
+func (m *defaultAuthorizationModel) FindByUser(ctx context.Context, userID string) ([]*Authorization, error) {
+	query := `select ` + authorizationRows + ` from ` + m.table + ` where user_id = ? order by id`
+	var resp []*Authorization
+	err := m.conn.QueryRowsCtx(ctx, &resp, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Revoke 将用户对客户端已批准的授权记录标记为已撤销
+func (m *defaultAuthorizationModel) Revoke(ctx context.Context, clientID, userID string) error {
+	query := `update ` + m.table + ` set status = ?, updated_at = ? where client_id = ? and user_id = ? and status = ?`
+	_, err := m.conn.ExecCtx(ctx, query, AuthorizationStatusRevoked, time.Now(), clientID, userID, AuthorizationStatusApproved)
+	return err
+}
+
+// FindUserIDsByClient 查询授权过客户端的全部用户ID
+func (m *defaultAuthorizationModel) FindUserIDsByClient(ctx context.Context, clientID string) ([]string, error) {
+	query := `select distinct user_id from ` + m.table + ` where client_id = ?`
+	var resp []string
+	err := m.conn.QueryRowsCtx(ctx, &resp, query, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteByClient 删除客户端的全部授权记录
+func (m *defaultAuthorizationModel) DeleteByClient(ctx context.Context, clientID string) error {
+	query := `delete from ` + m.table + ` where client_id = ?`
+	_, err := m.conn.ExecCtx(ctx, query, clientID)
+	return err
+}
+
 func (m *defaultAuthorizationModel) Update(ctx context.Context, data *Authorization) error {
 	data.UpdatedAt = time.Now()
 	query := `update ` + m.table + ` set ` + authorizationRowsWithPlaceHolder + ` where id = ?`
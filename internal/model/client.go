@@ -1,19 +1,105 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
 // Client 客户端信息表
 type Client struct {
-	ID          string    `db:"id" json:"id"`                     // 客户端ID
-	Secret      string    `db:"secret" json:"secret"`             // 客户端密钥
-	Name        string    `db:"name" json:"name"`                 // 应用名称
-	RedirectURL string    `db:"redirect_url" json:"redirect_url"` // 回调地址
-	GrantType   string    `db:"grant_type" json:"grant_type"`     // 支持的授权模式
-	Scope       string    `db:"scope" json:"scope"`               // 请求的权限范围
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`     // 创建时间
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`     // 更新时间
+	ID                      string    `db:"id" json:"id"`                                                 // 客户端ID
+	Secret                  string    `db:"secret" json:"-"`                                              // 客户端密钥的加盐哈希
+	Name                    string    `db:"name" json:"name"`                                             // 应用名称
+	RedirectURL             string    `db:"redirect_url" json:"redirect_url"`                             // 回调地址，多个地址以空格分隔
+	GrantType               string    `db:"grant_type" json:"grant_type"`                                 // 允许的授权模式，多个以空格分隔
+	Scope                   string    `db:"scope" json:"scope"`                                           // 请求的权限范围
+	RequirePKCE             bool      `db:"require_pkce" json:"require_pkce"`                             // 是否强制使用PKCE
+	TokenEndpointAuthMethod string    `db:"token_endpoint_auth_method" json:"token_endpoint_auth_method"` // 令牌端点认证方式
+	LogoURI                 string    `db:"logo_uri" json:"logo_uri"`                                     // 应用图标地址
+	JWKS                    string    `db:"jwks" json:"jwks"`                                             // 客户端公钥集合（JSON）
+	RegistrationToken       string    `db:"registration_token" json:"-"`                                  // 注册访问令牌的加盐哈希，为空表示不支持RFC 7592管理
+	Status                  string    `db:"status" json:"status"`                                         // 状态：active/pending
+	CreatedAt               time.Time `db:"created_at" json:"created_at"`                                 // 创建时间
+	UpdatedAt               time.Time `db:"updated_at" json:"updated_at"`                                 // 更新时间
+}
+
+// 令牌端点认证方式
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic"
+	AuthMethodClientSecretPost  = "client_secret_post"
+	AuthMethodNone              = "none"
+)
+
+// RedirectURIs 返回注册的全部回调地址
+func (c *Client) RedirectURIs() []string {
+	return strings.Fields(c.RedirectURL)
+}
+
+// SetRedirectURIs 设置注册的回调地址
+func (c *Client) SetRedirectURIs(uris []string) {
+	c.RedirectURL = strings.Join(uris, " ")
+}
+
+// 客户端状态
+const (
+	ClientStatusActive  = "active"
+	ClientStatusPending = "pending" // 等待管理员审批，不能用于授权和签发令牌
+)
+
+// IsActive 判断客户端是否可以用于授权和签发令牌
+func (c *Client) IsActive() bool {
+	return c.Status == ClientStatusActive
+}
+
+// IsPublic 判断是否为不持有密钥的公开客户端
+func (c *Client) IsPublic() bool {
+	return c.TokenEndpointAuthMethod == AuthMethodNone
+}
+
+// responseTypeGrants 授权端点响应类型对应的授权模式
+var responseTypeGrants = map[string]string{
+	"code":  "authorization_code",
+	"token": "implicit",
+}
+
+// GrantTypes 返回允许使用的授权模式
+func (c *Client) GrantTypes() []string {
+	return strings.Fields(c.GrantType)
+}
+
+// SetGrantTypes 设置允许使用的授权模式
+func (c *Client) SetGrantTypes(grantTypes []string) {
+	c.GrantType = strings.Join(grantTypes, " ")
+}
+
+// AllowsGrantType 判断客户端是否允许在令牌端点使用该授权模式，公开客户端始终不能使用客户端凭证模式
+func (c *Client) AllowsGrantType(grantType string) bool {
+	if grantType == "client_credentials" && c.IsPublic() {
+		return false
+	}
+	for _, gt := range c.GrantTypes() {
+		if gt == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsResponseType 判断客户端是否允许在授权端点使用该响应类型
+func (c *Client) AllowsResponseType(responseType string) bool {
+	grantType, ok := responseTypeGrants[responseType]
+	return ok && c.AllowsGrantType(grantType)
+}
+
+// ResponseTypes 返回允许使用的响应类型
+func (c *Client) ResponseTypes() []string {
+	responseTypes := make([]string, 0)
+	for _, rt := range []string{"code", "token"} {
+		if c.AllowsResponseType(rt) {
+			responseTypes = append(responseTypes, rt)
+		}
+	}
+	return responseTypes
 }
 
 // ClientRegisterReq 客户端注册请求
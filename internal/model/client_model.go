@@ -13,6 +13,8 @@ type ClientModel interface {
 	Insert(ctx context.Context, data *Client) (sql.Result, error)
 	FindOne(ctx context.Context, id string) (*Client, error)
 	FindAll(ctx context.Context) ([]*Client, error)
+	FindPage(ctx context.Context, status string, page, pageSize int64) ([]*Client, error)
+	Count(ctx context.Context, status string) (int64, error)
 	Update(ctx context.Context, data *Client) error
 	Delete(ctx context.Context, id string) error
 	FindByID(ctx context.Context, id string) (*Client, error)
@@ -31,20 +33,24 @@ func NewClientModel(conn sqlx.SqlConn) ClientModel {
 }
 
 func (m *defaultClientModel) Insert(ctx context.Context, data *Client) (sql.Result, error) {
-	// 生成客户端ID和密钥
+	// 生成客户端ID，密钥哈希由调用方生成
 	if data.ID == "" {
 		data.ID = "client_" + uuid.New().String()[:8]
 	}
-	if data.Secret == "" {
-		data.Secret = uuid.New().String()
+	if data.Status == "" {
+		data.Status = ClientStatusActive
+	}
+	if data.TokenEndpointAuthMethod == "" {
+		data.TokenEndpointAuthMethod = AuthMethodClientSecretBasic
 	}
 
 	now := time.Now()
 	data.CreatedAt = now
 	data.UpdatedAt = now
 
-	query := `insert into ` + m.table + ` (` + clientRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?, ?, ?)`
-	return m.conn.ExecCtx(ctx, query, data.ID, data.Secret, data.Name, data.RedirectURL, data.GrantType, data.Scope, data.CreatedAt, data.UpdatedAt)
+	query := `insert into ` + m.table + ` (` + clientRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	return m.conn.ExecCtx(ctx, query, data.ID, data.Secret, data.Name, data.RedirectURL, data.GrantType, data.Scope, data.RequirePKCE,
+		data.TokenEndpointAuthMethod, data.LogoURI, data.JWKS, data.RegistrationToken, data.Status, data.CreatedAt, data.UpdatedAt)
 }
 
 func (m *defaultClientModel) FindOne(ctx context.Context, id string) (*Client, error) {
@@ -71,10 +77,29 @@ func (m *defaultClientModel) FindAll(ctx context.Context) ([]*Client, error) {
 	return resp, nil
 }
 
+// FindPage 按创建时间分页查询客户端，page从1开始，status为空时查询全部状态
+func (m *defaultClientModel) FindPage(ctx context.Context, status string, page, pageSize int64) ([]*Client, error) {
+	query := `select ` + clientRows + ` from ` + m.table + ` where ? = '' or status = ? order by created_at, id limit ?, ?`
+	var resp []*Client
+	err := m.conn.QueryRowsCtx(ctx, &resp, query, status, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (m *defaultClientModel) Count(ctx context.Context, status string) (int64, error) {
+	query := `select count(*) from ` + m.table + ` where ? = '' or status = ?`
+	var count int64
+	err := m.conn.QueryRowCtx(ctx, &count, query, status, status)
+	return count, err
+}
+
 func (m *defaultClientModel) Update(ctx context.Context, data *Client) error {
 	data.UpdatedAt = time.Now()
 	query := `update ` + m.table + ` set ` + clientRowsWithPlaceHolder + ` where id = ?`
-	_, err := m.conn.ExecCtx(ctx, query, data.Secret, data.Name, data.RedirectURL, data.GrantType, data.Scope, data.UpdatedAt, data.ID)
+	_, err := m.conn.ExecCtx(ctx, query, data.Secret, data.Name, data.RedirectURL, data.GrantType, data.Scope, data.RequirePKCE,
+		data.TokenEndpointAuthMethod, data.LogoURI, data.JWKS, data.RegistrationToken, data.Status, data.UpdatedAt, data.ID)
 	return err
 }
 
@@ -89,9 +114,9 @@ func (m *defaultClientModel) FindByID(ctx context.Context, id string) (*Client,
 }
 
 var (
-	clientRows                = "id, secret, name, redirect_url, grant_type, scope, created_at, updated_at"
-	clientRowsExpectAutoSet   = "id, secret, name, redirect_url, grant_type, scope, created_at, updated_at"
-	clientRowsWithPlaceHolder = "secret = ?, name = ?, redirect_url = ?, grant_type = ?, scope = ?, updated_at = ?"
+	clientRows                = "id, secret, name, redirect_url, grant_type, scope, require_pkce, token_endpoint_auth_method, logo_uri, jwks, registration_token, status, created_at, updated_at"
+	clientRowsExpectAutoSet   = "id, secret, name, redirect_url, grant_type, scope, require_pkce, token_endpoint_auth_method, logo_uri, jwks, registration_token, status, created_at, updated_at"
+	clientRowsWithPlaceHolder = "secret = ?, name = ?, redirect_url = ?, grant_type = ?, scope = ?, require_pkce = ?, token_endpoint_auth_method = ?, logo_uri = ?, jwks = ?, registration_token = ?, status = ?, updated_at = ?"
 )
 
 var ErrNotFound = sql.ErrNoRows
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// InitialAccessToken 客户端注册使用的初始访问令牌表
+type InitialAccessToken struct {
+	ID          string       `db:"id" json:"id"`                   // 令牌ID，也是令牌明文的前缀
+	TokenHash   string       `db:"token_hash" json:"-"`            // 令牌密钥部分的加盐哈希
+	Description string       `db:"description" json:"description"` // 用途说明
+	ExpiresAt   sql.NullTime `db:"expires_at" json:"-"`            // 过期时间，为空表示永不过期
+	MaxUses     int64        `db:"max_uses" json:"max_uses"`       // 最多可注册的客户端数量，0表示不限
+	Uses        int64        `db:"uses" json:"uses"`               // 已注册的客户端数量
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`   // 创建时间
+}
+
+// Usable 判断令牌是否未过期且未用完
+func (t *InitialAccessToken) Usable(now time.Time) bool {
+	if t.ExpiresAt.Valid && !now.Before(t.ExpiresAt.Time) {
+		return false
+	}
+	return t.MaxUses == 0 || t.Uses < t.MaxUses
+}
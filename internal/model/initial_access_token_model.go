@@ -0,0 +1,86 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type InitialAccessTokenModel interface {
+	Insert(ctx context.Context, data *InitialAccessToken) (sql.Result, error)
+	FindOne(ctx context.Context, id string) (*InitialAccessToken, error)
+	FindAll(ctx context.Context) ([]*InitialAccessToken, error)
+	Consume(ctx context.Context, id string) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type defaultInitialAccessTokenModel struct {
+	conn  sqlx.SqlConn
+	table string
+}
+
+func NewInitialAccessTokenModel(conn sqlx.SqlConn) InitialAccessTokenModel {
+	return &defaultInitialAccessTokenModel{
+		conn:  conn,
+		table: "`initial_access_token`",
+	}
+}
+
+func (m *defaultInitialAccessTokenModel) Insert(ctx context.Context, data *InitialAccessToken) (sql.Result, error) {
+	if data.ID == "" {
+		data.ID = "iat_" + uuid.New().String()[:8]
+	}
+	data.CreatedAt = time.Now()
+
+	query := `insert into ` + m.table + ` (` + initialAccessTokenRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?, ?)`
+	return m.conn.ExecCtx(ctx, query, data.ID, data.TokenHash, data.Description, data.ExpiresAt, data.MaxUses, data.Uses, data.CreatedAt)
+}
+
+func (m *defaultInitialAccessTokenModel) FindOne(ctx context.Context, id string) (*InitialAccessToken, error) {
+	query := `select ` + initialAccessTokenRows + ` from ` + m.table + ` where id = ? limit 1`
+	var resp InitialAccessToken
+	err := m.conn.QueryRowCtx(ctx, &resp, query, id)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sql.ErrNoRows:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultInitialAccessTokenModel) FindAll(ctx context.Context) ([]*InitialAccessToken, error) {
+	query := `select ` + initialAccessTokenRows + ` from ` + m.table + ` order by created_at, id`
+	var resp []*InitialAccessToken
+	err := m.conn.QueryRowsCtx(ctx, &resp, query)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Consume 原子地使用一次令牌，令牌已过期或已用完时返回false
+func (m *defaultInitialAccessTokenModel) Consume(ctx context.Context, id string) (bool, error) {
+	query := `update ` + m.table + ` set uses = uses + 1 where id = ? and (max_uses = 0 or uses < max_uses) and (expires_at is null or expires_at > ?)`
+	result, err := m.conn.ExecCtx(ctx, query, id, time.Now())
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (m *defaultInitialAccessTokenModel) Delete(ctx context.Context, id string) error {
+	query := `delete from ` + m.table + ` where id = ?`
+	_, err := m.conn.ExecCtx(ctx, query, id)
+	return err
+}
+
+var (
+	initialAccessTokenRows              = "id, token_hash, description, expires_at, max_uses, uses, created_at"
+	initialAccessTokenRowsExpectAutoSet = "id, token_hash, description, expires_at, max_uses, uses, created_at"
+)
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope 权限范围表
+type Scope struct {
+	Name        string    `db:"name" json:"name"`               // 权限名称
+	Description string    `db:"description" json:"description"` // 授权页面展示的说明
+	Sensitive   bool      `db:"sensitive" json:"sensitive"`     // 是否为敏感权限
+	Implies     string    `db:"implies" json:"implies"`         // 隐含的其他权限，以空格分隔
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`   // 创建时间
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`   // 更新时间
+}
+
+// ImpliedScopes 返回该权限隐含的其他权限
+func (s *Scope) ImpliedScopes() []string {
+	return strings.Fields(s.Implies)
+}
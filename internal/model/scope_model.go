@@ -0,0 +1,81 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type ScopeModel interface {
+	Insert(ctx context.Context, data *Scope) (sql.Result, error)
+	FindOne(ctx context.Context, name string) (*Scope, error)
+	FindAll(ctx context.Context) ([]*Scope, error)
+	Update(ctx context.Context, data *Scope) error
+	Delete(ctx context.Context, name string) error
+}
+
+type defaultScopeModel struct {
+	conn  sqlx.SqlConn
+	table string
+}
+
+func NewScopeModel(conn sqlx.SqlConn) ScopeModel {
+	return &defaultScopeModel{
+		conn:  conn,
+		table: "`scope`",
+	}
+}
+
+func (m *defaultScopeModel) Insert(ctx context.Context, data *Scope) (sql.Result, error) {
+	now := time.Now()
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	query := `insert into ` + m.table + ` (` + scopeRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?)`
+	return m.conn.ExecCtx(ctx, query, data.Name, data.Description, data.Sensitive, data.Implies, data.CreatedAt, data.UpdatedAt)
+}
+
+func (m *defaultScopeModel) FindOne(ctx context.Context, name string) (*Scope, error) {
+	query := `select ` + scopeRows + ` from ` + m.table + ` where name = ? limit 1`
+	var resp Scope
+	err := m.conn.QueryRowCtx(ctx, &resp, query, name)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sql.ErrNoRows:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultScopeModel) FindAll(ctx context.Context) ([]*Scope, error) {
+	query := `select ` + scopeRows + ` from ` + m.table + ` order by name`
+	var resp []*Scope
+	err := m.conn.QueryRowsCtx(ctx, &resp, query)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (m *defaultScopeModel) Update(ctx context.Context, data *Scope) error {
+	data.UpdatedAt = time.Now()
+	query := `update ` + m.table + ` set ` + scopeRowsWithPlaceHolder + ` where name = ?`
+	_, err := m.conn.ExecCtx(ctx, query, data.Description, data.Sensitive, data.Implies, data.UpdatedAt, data.Name)
+	return err
+}
+
+func (m *defaultScopeModel) Delete(ctx context.Context, name string) error {
+	query := `delete from ` + m.table + ` where name = ?`
+	_, err := m.conn.ExecCtx(ctx, query, name)
+	return err
+}
+
+var (
+	scopeRows                = "name, description, sensitive, implies, created_at, updated_at"
+	scopeRowsExpectAutoSet   = "name, description, sensitive, implies, created_at, updated_at"
+	scopeRowsWithPlaceHolder = "description = ?, sensitive = ?, implies = ?, updated_at = ?"
+)
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// User 用户信息表
+type User struct {
+	ID           string    `db:"id" json:"id"`                 // 用户ID
+	Username     string    `db:"username" json:"username"`     // 用户名
+	PasswordHash string    `db:"password_hash" json:"-"`       // 密码哈希（bcrypt）
+	Phone        string    `db:"phone" json:"phone"`           // 手机号
+	CreatedAt    time.Time `db:"created_at" json:"created_at"` // 创建时间
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"` // 更新时间
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type UserModel interface {
+	Insert(ctx context.Context, data *User) (sql.Result, error)
+	FindOne(ctx context.Context, id string) (*User, error)
+	FindByUsername(ctx context.Context, username string) (*User, error)
+	Update(ctx context.Context, data *User) error
+}
+
+type defaultUserModel struct {
+	conn  sqlx.SqlConn
+	table string
+}
+
+func NewUserModel(conn sqlx.SqlConn) UserModel {
+	return &defaultUserModel{
+		conn:  conn,
+		table: "`user`",
+	}
+}
+
+func (m *defaultUserModel) Insert(ctx context.Context, data *User) (sql.Result, error) {
+	// 生成用户ID
+	if data.ID == "" {
+		data.ID = "user_" + uuid.New().String()[:8]
+	}
+
+	now := time.Now()
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	query := `insert into ` + m.table + ` (` + userRowsExpectAutoSet + `) values (?, ?, ?, ?, ?, ?)`
+	return m.conn.ExecCtx(ctx, query, data.ID, data.Username, data.PasswordHash, data.Phone, data.CreatedAt, data.UpdatedAt)
+}
+
+func (m *defaultUserModel) FindOne(ctx context.Context, id string) (*User, error) {
+	query := `select ` + userRows + ` from ` + m.table + ` where id = ? limit 1`
+	var resp User
+	err := m.conn.QueryRowCtx(ctx, &resp, query, id)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sql.ErrNoRows:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultUserModel) FindByUsername(ctx context.Context, username string) (*User, error) {
+	query := `select ` + userRows + ` from ` + m.table + ` where username = ? limit 1`
+	var resp User
+	err := m.conn.QueryRowCtx(ctx, &resp, query, username)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sql.ErrNoRows:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultUserModel) Update(ctx context.Context, data *User) error {
+	data.UpdatedAt = time.Now()
+	query := `update ` + m.table + ` set ` + userRowsWithPlaceHolder + ` where id = ?`
+	_, err := m.conn.ExecCtx(ctx, query, data.Username, data.PasswordHash, data.Phone, data.UpdatedAt, data.ID)
+	return err
+}
+
+var (
+	userRows                = "id, username, password_hash, phone, created_at, updated_at"
+	userRowsExpectAutoSet   = "id, username, password_hash, phone, created_at, updated_at"
+	userRowsWithPlaceHolder = "username = ?, password_hash = ?, phone = ?, updated_at = ?"
+)
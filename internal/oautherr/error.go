@@ -0,0 +1,161 @@
+package oautherr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	oauth2errors "github.com/go-oauth2/oauth2/v4/errors"
+)
+
+// 错误码（RFC 6749 4.1.2.1、5.2，RFC 6750 3.1，RFC 7009 2.2.1）
+const (
+	InvalidRequest          = "invalid_request"
+	InvalidClient           = "invalid_client"
+	InvalidGrant            = "invalid_grant"
+	UnauthorizedClient      = "unauthorized_client"
+	UnsupportedGrantType    = "unsupported_grant_type"
+	UnsupportedResponseType = "unsupported_response_type"
+	InvalidScope            = "invalid_scope"
+	AccessDenied            = "access_denied"
+	ServerError             = "server_error"
+	TemporarilyUnavailable  = "temporarily_unavailable"
+	InvalidToken            = "invalid_token"
+	InsufficientScope       = "insufficient_scope"
+	UnsupportedTokenType    = "unsupported_token_type"
+)
+
+// statusCodes 错误码对应的HTTP状态码，未列出的错误码为400
+var statusCodes = map[string]int{
+	InvalidClient:          http.StatusUnauthorized,
+	AccessDenied:           http.StatusForbidden,
+	ServerError:            http.StatusInternalServerError,
+	TemporarilyUnavailable: http.StatusServiceUnavailable,
+	InvalidToken:           http.StatusUnauthorized,
+	InsufficientScope:      http.StatusForbidden,
+}
+
+// tokenErrors go-oauth2中没有对应错误码的令牌错误
+var tokenErrors = map[error]string{
+	oauth2errors.ErrInvalidRedirectURI:   InvalidRequest,
+	oauth2errors.ErrInvalidAuthorizeCode: InvalidGrant,
+	oauth2errors.ErrInvalidRefreshToken:  InvalidGrant,
+	oauth2errors.ErrExpiredRefreshToken:  InvalidGrant,
+	oauth2errors.ErrMissingCodeVerifier:  InvalidGrant,
+	oauth2errors.ErrInvalidCodeChallenge: InvalidGrant,
+	oauth2errors.ErrMissingCodeChallenge: InvalidRequest,
+	oauth2errors.ErrInvalidAccessToken:   InvalidToken,
+	oauth2errors.ErrExpiredAccessToken:   InvalidToken,
+}
+
+// ErrMissingToken 访问受保护资源时没有携带Bearer令牌，按RFC 6750只返回认证方式，不返回错误码
+var ErrMissingToken = errors.New("missing bearer token")
+
+// Error OAuth2错误响应
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// New 创建OAuth2错误
+func New(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// StatusCode 返回错误码对应的HTTP状态码
+func (e *Error) StatusCode() int {
+	return StatusCode(e.Code)
+}
+
+// Response 转换为go-oauth2的错误响应
+func (e *Error) Response() *oauth2errors.Response {
+	re := &oauth2errors.Response{
+		Error:       oauth2errors.New(e.Code),
+		Description: e.Description,
+		StatusCode:  e.StatusCode(),
+	}
+	Normalize(re)
+	return re
+}
+
+// StatusCode 返回错误码对应的HTTP状态码
+func StatusCode(code string) int {
+	if status, ok := statusCodes[code]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+// From 将错误转换为OAuth2错误，go-oauth2的错误映射到对应的错误码，其余错误视为server_error
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	if description, ok := oauth2errors.Descriptions[err]; ok {
+		return New(err.Error(), description)
+	}
+	if code, ok := tokenErrors[err]; ok {
+		return New(code, err.Error())
+	}
+	return New(ServerError, "")
+}
+
+// Normalize 按RFC 6749修正go-oauth2错误响应的状态码，invalid_client附带WWW-Authenticate
+func Normalize(re *oauth2errors.Response) {
+	if re.Error == nil {
+		return
+	}
+	code := re.Error.Error()
+	re.StatusCode = StatusCode(code)
+	if code == InvalidClient {
+		if re.Header == nil {
+			re.Header = make(http.Header)
+		}
+		re.Header.Set("WWW-Authenticate", basicChallenge)
+	}
+}
+
+const basicChallenge = `Basic realm="oauth2"`
+
+// Write 按RFC 6749 5.2输出JSON错误，客户端认证失败时附带WWW-Authenticate
+func Write(w http.ResponseWriter, err error) {
+	e := From(err)
+	if e.Code == InvalidClient {
+		w.Header().Set("WWW-Authenticate", basicChallenge)
+	}
+	writeJSON(w, e.StatusCode(), e)
+}
+
+// WriteBearer 按RFC 6750 3.1输出受保护资源的错误，错误码同时写入WWW-Authenticate
+func WriteBearer(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrMissingToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	e := From(err)
+	challenge := fmt.Sprintf("Bearer error=%q", e.Code)
+	if e.Description != "" {
+		challenge += fmt.Sprintf(", error_description=%q", e.Description)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	writeJSON(w, e.StatusCode(), e)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
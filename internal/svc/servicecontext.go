@@ -2,10 +2,13 @@ package svc
 
 import (
 	"oauth2-server/internal/config"
+	"oauth2-server/internal/middleware"
 	"oauth2-server/internal/model"
+	"oauth2-server/internal/util"
 
 	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
+	"github.com/zeromicro/go-zero/rest"
 )
 
 type ServiceContext struct {
@@ -14,16 +17,32 @@ type ServiceContext struct {
 	Redis              redis.Redis
 	ClientModel        model.ClientModel
 	AuthorizationModel model.AuthorizationModel
+	UserModel          model.UserModel
+	ScopeModel         model.ScopeModel
+	InitialTokenModel  model.InitialAccessTokenModel
+	KeySet             *util.KeySet
+	AdminVerifier      util.AdminVerifier
+	AdminAuth          rest.Middleware
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	conn := sqlx.NewMysql(c.MySQL.DataSource)
+	rds := redis.MustNewRedis(c.Redis)
+	redisStore := util.NewRedisStore(*rds)
+	keySet := util.MustNewKeySet(c.Auth, redisStore)
+	adminVerifier := util.NewJWTAdminVerifier(keySet, redisStore)
 
 	return &ServiceContext{
 		Config:             c,
 		DB:                 conn,
-		Redis:              *redis.MustNewRedis(c.Redis),
-		ClientModel:        model.NewClientModel(conn),
+		Redis:              *rds,
+		ClientModel:        util.MustNewClientStore(model.NewClientModel(conn), c.ClientCache),
 		AuthorizationModel: model.NewAuthorizationModel(conn),
+		UserModel:          model.NewUserModel(conn),
+		ScopeModel:         model.NewScopeModel(conn),
+		InitialTokenModel:  model.NewInitialAccessTokenModel(conn),
+		KeySet:             keySet,
+		AdminVerifier:      adminVerifier,
+		AdminAuth:          middleware.NewAdminAuthMiddleware(adminVerifier).Handle,
 	}
 }
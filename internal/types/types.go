@@ -2,49 +2,221 @@ package types
 
 // ClientRegisterReq 客户端注册请求
 type ClientRegisterReq struct {
-	Name        string `json:"name"`         // 应用名称
-	RedirectURL string `json:"redirect_url"` // 回调地址
-	GrantType   string `json:"grant_type"`   // 支持的授权模式
-	Scope       string `json:"scope"`        // 请求的权限范围
+	Authorization string   `header:"Authorization,optional"` // 携带admin访问令牌或初始访问令牌的Bearer认证头
+	Name          string   `json:"name"`                     // 应用名称
+	RedirectURL   string   `json:"redirect_url,optional"`    // 回调地址，只注册一个地址时可使用
+	RedirectURIs  []string `json:"redirect_uris,optional"`   // 回调地址列表
+	GrantType     string   `json:"grant_type"`               // 允许的授权模式，多个以空格分隔
+	Scope         string   `json:"scope"`                    // 请求的权限范围
+	RequirePKCE   bool     `json:"require_pkce,optional"`    // 是否强制使用PKCE，公开客户端和移动端应开启
 }
 
 // ClientRegisterResp 客户端注册响应
 type ClientRegisterResp struct {
 	ClientID     string `json:"client_id"`     // 客户端ID
 	ClientSecret string `json:"client_secret"` // 客户端密钥
+	Status       string `json:"status"`        // 状态：active/pending
+}
+
+// ClientReq 客户端查询/删除请求
+type ClientReq struct {
+	ID string `path:"id"` // 客户端ID
+}
+
+// ClientUpdateReq 客户端更新请求
+type ClientUpdateReq struct {
+	ID           string   `path:"id"`                     // 客户端ID
+	Name         string   `json:"name"`                   // 应用名称
+	RedirectURL  string   `json:"redirect_url,optional"`  // 回调地址，只注册一个地址时可使用
+	RedirectURIs []string `json:"redirect_uris,optional"` // 回调地址列表
+	GrantType    string   `json:"grant_type"`             // 允许的授权模式，多个以空格分隔
+	Scope        string   `json:"scope"`                  // 请求的权限范围
+	RequirePKCE  bool     `json:"require_pkce,optional"`  // 是否强制使用PKCE
+}
+
+// ClientInfo 客户端信息，不包含密钥
+type ClientInfo struct {
+	ClientID      string   `json:"client_id"`      // 客户端ID
+	Name          string   `json:"name"`           // 应用名称
+	RedirectURIs  []string `json:"redirect_uris"`  // 回调地址列表
+	GrantType     string   `json:"grant_type"`     // 允许的授权模式，多个以空格分隔
+	ResponseTypes []string `json:"response_types"` // 允许的响应类型
+	Scope         string   `json:"scope"`          // 请求的权限范围
+	RequirePKCE   bool     `json:"require_pkce"`   // 是否强制使用PKCE
+	Status        string   `json:"status"`         // 状态：active/pending
+	CreatedAt     int64    `json:"created_at"`     // 创建时间
+	UpdatedAt     int64    `json:"updated_at"`     // 更新时间
+}
+
+// ClientListReq 客户端列表请求
+type ClientListReq struct {
+	Page     int64  `form:"page,default=1,range=[1:]"`              // 页码，从1开始
+	PageSize int64  `form:"page_size,default=20,range=[1:100]"`     // 每页数量
+	Status   string `form:"status,optional,options=active|pending"` // 按状态过滤，为空时返回全部
+}
+
+// ClientListResp 客户端列表响应
+type ClientListResp struct {
+	Total    int64        `json:"total"`     // 客户端总数
+	Page     int64        `json:"page"`      // 页码
+	PageSize int64        `json:"page_size"` // 每页数量
+	Clients  []ClientInfo `json:"clients"`   // 客户端列表
+}
+
+// ClientSecretResp 重新生成客户端密钥响应
+type ClientSecretResp struct {
+	ClientID     string `json:"client_id"`     // 客户端ID
+	ClientSecret string `json:"client_secret"` // 新的客户端密钥
+}
+
+// DynamicClientRegisterReq RFC 7591 动态客户端注册请求
+type DynamicClientRegisterReq struct {
+	Authorization           string                 `header:"Authorization,optional"`            // 携带admin访问令牌或初始访问令牌的Bearer认证头
+	RedirectURIs            []string               `json:"redirect_uris,optional"`              // 回调地址列表
+	GrantTypes              []string               `json:"grant_types,optional"`                // 允许的授权模式，默认authorization_code
+	ResponseTypes           []string               `json:"response_types,optional"`             // 允许的响应类型，默认由授权模式推导
+	TokenEndpointAuthMethod string                 `json:"token_endpoint_auth_method,optional"` // 令牌端点认证方式，默认client_secret_basic
+	ClientName              string                 `json:"client_name,optional"`                // 应用名称
+	LogoURI                 string                 `json:"logo_uri,optional"`                   // 应用图标地址
+	JWKS                    map[string]interface{} `json:"jwks,optional"`                       // 客户端公钥集合
+	Scope                   string                 `json:"scope,optional"`                      // 请求的权限范围
+}
+
+// InitialAccessTokenCreateReq 创建初始访问令牌请求
+type InitialAccessTokenCreateReq struct {
+	Description string `json:"description,optional"`           // 用途说明
+	ExpiresIn   int64  `json:"expires_in,optional,range=[0:]"` // 有效期（秒），0表示永不过期
+	MaxUses     int64  `json:"max_uses,default=1,range=[0:]"`  // 最多可注册的客户端数量，默认1次，0表示不限
+}
+
+// InitialAccessTokenReq 初始访问令牌删除请求
+type InitialAccessTokenReq struct {
+	ID string `path:"id"` // 令牌ID
+}
+
+// InitialAccessTokenInfo 初始访问令牌信息，不包含令牌明文
+type InitialAccessTokenInfo struct {
+	ID          string `json:"id"`          // 令牌ID
+	Description string `json:"description"` // 用途说明
+	ExpiresAt   int64  `json:"expires_at"`  // 过期时间，0表示永不过期
+	MaxUses     int64  `json:"max_uses"`    // 最多可注册的客户端数量，0表示不限
+	Uses        int64  `json:"uses"`        // 已注册的客户端数量
+	CreatedAt   int64  `json:"created_at"`  // 创建时间
+}
+
+// InitialAccessTokenCreateResp 创建初始访问令牌响应，令牌明文只返回一次
+type InitialAccessTokenCreateResp struct {
+	Token     string `json:"token"`      // 令牌明文
+	ID        string `json:"id"`         // 令牌ID
+	ExpiresAt int64  `json:"expires_at"` // 过期时间，0表示永不过期
+	MaxUses   int64  `json:"max_uses"`   // 最多可注册的客户端数量，0表示不限
+}
+
+// RotateSigningKeyReq 轮换签名密钥请求
+type RotateSigningKeyReq struct {
+	Immediate bool `json:"immediate,optional"` // 为true时新密钥在其他实例同步后（1分钟）即接替签名，否则提前发布RotatePrepublish秒后再启用
+}
+
+// RotateSigningKeyResp 轮换签名密钥响应
+type RotateSigningKeyResp struct {
+	KeyID     string `json:"kid"`        // 新密钥ID
+	NotBefore int64  `json:"not_before"` // 开始用于签名的时间
+}
+
+// InitialAccessTokenListResp 初始访问令牌列表响应
+type InitialAccessTokenListResp struct {
+	Tokens []InitialAccessTokenInfo `json:"tokens"` // 令牌列表
+}
+
+// DynamicClientReq RFC 7592 客户端读取/删除请求
+type DynamicClientReq struct {
+	ClientID      string `path:"client_id"`                // 客户端ID
+	Authorization string `header:"Authorization,optional"` // 携带注册访问令牌的Bearer认证头
+}
+
+// DynamicClientUpdateReq RFC 7592 客户端更新请求，使用请求中的元数据整体替换
+type DynamicClientUpdateReq struct {
+	ID                      string                 `path:"client_id"`                           // 客户端ID
+	Authorization           string                 `header:"Authorization,optional"`            // 携带注册访问令牌的Bearer认证头
+	ClientID                string                 `json:"client_id,optional"`                  // 必须与地址中的客户端ID一致
+	RedirectURIs            []string               `json:"redirect_uris,optional"`              // 回调地址列表
+	GrantTypes              []string               `json:"grant_types,optional"`                // 允许的授权模式
+	ResponseTypes           []string               `json:"response_types,optional"`             // 允许的响应类型
+	TokenEndpointAuthMethod string                 `json:"token_endpoint_auth_method,optional"` // 令牌端点认证方式
+	ClientName              string                 `json:"client_name,optional"`                // 应用名称
+	LogoURI                 string                 `json:"logo_uri,optional"`                   // 应用图标地址
+	JWKS                    map[string]interface{} `json:"jwks,optional"`                       // 客户端公钥集合
+	Scope                   string                 `json:"scope,optional"`                      // 请求的权限范围
 }
 
 // AuthorizeReq 授权请求
 type AuthorizeReq struct {
-	ClientID     string `form:"client_id"`     // 客户端ID
-	ResponseType string `form:"response_type"` // 响应类型
-	RedirectURI  string `form:"redirect_uri"`  // 重定向URI
-	Scope        string `form:"scope"`         // 权限范围
-	State        string `form:"state"`         // 状态参数
+	ClientID            string `form:"client_id"`                      // 客户端ID
+	ResponseType        string `form:"response_type,optional"`         // 响应类型，缺失时在回调地址验证通过后按invalid_request重定向
+	RedirectURI         string `form:"redirect_uri,optional"`          // 重定向URI，只注册了一个地址时可省略
+	Scope               string `form:"scope,optional"`                 // 权限范围，未指定时使用客户端注册的全部权限
+	State               string `form:"state,optional"`                 // 状态参数，原样带回客户端
+	CodeChallenge       string `form:"code_challenge,optional"`        // PKCE挑战码
+	CodeChallengeMethod string `form:"code_challenge_method,optional"` // PKCE挑战方法：S256/plain
+	Nonce               string `form:"nonce,optional"`                 // OpenID Connect随机数，原样写入ID Token
 }
 
-// AuthorizeResp 授权响应
+// AuthorizeResp 授权响应，以查询参数的形式重定向回客户端
 type AuthorizeResp struct {
-	Code  string `json:"code"`  // 授权码
-	State string `json:"state"` // 状态参数
+	RedirectURI      string `json:"redirect_uri"`                // 回调地址
+	Code             string `json:"code,omitempty"`              // 授权码
+	Error            string `json:"error,omitempty"`             // 错误码，用户拒绝授权时为access_denied
+	ErrorDescription string `json:"error_description,omitempty"` // 错误描述
+	State            string `json:"state"`                       // 状态参数
 }
 
 // TokenReq Token请求
 type TokenReq struct {
-	GrantType    string `form:"grant_type"`    // 授权类型
-	Code         string `form:"code"`          // 授权码
-	RedirectURI  string `form:"redirect_uri"`  // 重定向URI
-	ClientID     string `form:"client_id"`     // 客户端ID
-	ClientSecret string `form:"client_secret"` // 客户端密钥
+	GrantType    string `form:"grant_type"`             // 授权类型
+	Code         string `form:"code,optional"`          // 授权码
+	RedirectURI  string `form:"redirect_uri,optional"`  // 重定向URI
+	RefreshToken string `form:"refresh_token,optional"` // 刷新令牌
+	Scope        string `form:"scope,optional"`         // 权限范围
+	CodeVerifier string `form:"code_verifier,optional"` // PKCE校验码
+	ClientID     string `form:"client_id,optional"`     // 客户端ID，也可通过Basic认证传递
+	ClientSecret string `form:"client_secret,optional"` // 客户端密钥，也可通过Basic认证传递
 }
 
 // TokenResp Token响应
 type TokenResp struct {
-	AccessToken  string `json:"access_token"`  // 访问令牌
-	TokenType    string `json:"token_type"`    // 令牌类型
-	ExpiresIn    int64  `json:"expires_in"`    // 过期时间
-	RefreshToken string `json:"refresh_token"` // 刷新令牌
-	Scope        string `json:"scope"`         // 权限范围
+	AccessToken  string `json:"access_token"`            // 访问令牌
+	TokenType    string `json:"token_type"`              // 令牌类型
+	ExpiresIn    int64  `json:"expires_in"`              // 过期时间
+	RefreshToken string `json:"refresh_token,omitempty"` // 刷新令牌，客户端凭证模式不颁发
+	Scope        string `json:"scope"`                   // 权限范围
+	IDToken      string `json:"id_token,omitempty"`      // OpenID Connect ID Token，请求openid权限时返回
+}
+
+// RevokeReq 令牌吊销请求（RFC 7009）
+type RevokeReq struct {
+	Token         string `form:"token"`                    // 待吊销的令牌
+	TokenTypeHint string `form:"token_type_hint,optional"` // 令牌类型提示：access_token/refresh_token
+	ClientID      string `form:"client_id,optional"`       // 客户端ID，也可通过Basic认证传递
+	ClientSecret  string `form:"client_secret,optional"`   // 客户端密钥，也可通过Basic认证传递
+}
+
+// IntrospectReq 令牌内省请求（RFC 7662）
+type IntrospectReq struct {
+	Token         string `form:"token"`                    // 待检查的令牌
+	TokenTypeHint string `form:"token_type_hint,optional"` // 令牌类型提示
+	ClientID      string `form:"client_id,optional"`       // 客户端ID，也可通过Basic认证传递
+	ClientSecret  string `form:"client_secret,optional"`   // 客户端密钥，也可通过Basic认证传递
+}
+
+// IntrospectResp 令牌内省响应
+type IntrospectResp struct {
+	Active   bool     `json:"active"`              // 令牌是否有效
+	Scope    string   `json:"scope,omitempty"`     // 权限范围
+	ClientID string   `json:"client_id,omitempty"` // 客户端ID
+	Sub      string   `json:"sub,omitempty"`       // 用户ID
+	Exp      int64    `json:"exp,omitempty"`       // 过期时间
+	Iat      int64    `json:"iat,omitempty"`       // 签发时间
+	Aud      []string `json:"aud,omitempty"`       // 受众
 }
 
 // UserInfoResp 用户信息响应
@@ -54,10 +226,15 @@ type UserInfoResp struct {
 	Phone    string `json:"phone"`    // 手机号
 }
 
+// GrantReq 撤销应用授权请求
+type GrantReq struct {
+	ClientID string `path:"client_id"` // 客户端ID
+}
+
 // LoginReq 登录请求
 type LoginReq struct {
-	Username string `json:"username"` // 用户名
-	Password string `json:"password"` // 密码
+	Username string `form:"username"`          // 用户名
+	Password string `form:"password,optional"` // 密码
 }
 
 // LoginResp 登录响应
@@ -0,0 +1,129 @@
+package util
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"oauth2-server/internal/model"
+)
+
+// AdminScope 管理客户端和初始访问令牌所需的权限
+const AdminScope = "admin"
+
+// ErrInvalidInitialAccessToken 注册请求既没有管理员令牌，也没有可用的初始访问令牌
+var ErrInvalidInitialAccessToken = errors.New("invalid_token")
+
+// AdminVerifier 判断Bearer令牌是否为有效且包含admin权限的访问令牌
+type AdminVerifier func(ctx context.Context, token string) bool
+
+// NewJWTAdminVerifier 校验本服务签发的JWT访问令牌，令牌必须仍保存在Redis中
+func NewJWTAdminVerifier(keys *KeySet, redisStore *RedisStore) AdminVerifier {
+	return func(ctx context.Context, token string) bool {
+		claims, err := ParseToken(token, keys)
+		if err != nil {
+			return false
+		}
+		data, err := redisStore.GetAccessToken(ctx, token)
+		if err != nil || data == "" {
+			return false
+		}
+		return HasAdminScope(claims.Scope)
+	}
+}
+
+// HasAdminScope 判断以空格分隔的scope中是否包含admin权限
+func HasAdminScope(scope string) bool {
+	return contains(ParseScope(scope), AdminScope)
+}
+
+// NewInitialAccessToken 生成初始访问令牌，明文格式为 <ID>.<密钥>，只返回给调用方一次。
+// expiresIn为有效期（秒），0表示永不过期；maxUses为0表示不限次数
+func NewInitialAccessToken(ctx context.Context, tokenModel model.InitialAccessTokenModel, description string,
+	expiresIn, maxUses int64) (*model.InitialAccessToken, string, error) {
+	secret, hash, err := NewClientSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	t := &model.InitialAccessToken{
+		TokenHash:   hash,
+		Description: description,
+		MaxUses:     maxUses,
+	}
+	if expiresIn > 0 {
+		t.ExpiresAt = sql.NullTime{Time: time.Now().Add(time.Duration(expiresIn) * time.Second), Valid: true}
+	}
+	if _, err := tokenModel.Insert(ctx, t); err != nil {
+		return nil, "", err
+	}
+	return t, t.ID + "." + secret, nil
+}
+
+// Registrant 客户端注册的调用方，管理员或持有初始访问令牌的开发者
+type Registrant struct {
+	Admin           bool
+	token           *model.InitialAccessToken
+	tokenModel      model.InitialAccessTokenModel
+	requireApproval bool
+}
+
+// AuthenticateRegistrant 校验注册请求的Bearer令牌，authorization为请求的Authorization头。
+// requireApproval为true时，使用初始访问令牌注册的客户端需要管理员审批
+func AuthenticateRegistrant(ctx context.Context, tokenModel model.InitialAccessTokenModel, isAdmin AdminVerifier,
+	authorization string, requireApproval bool) (*Registrant, error) {
+	bearer, ok := BearerToken(authorization)
+	if !ok {
+		return nil, ErrInvalidInitialAccessToken
+	}
+	if isAdmin(ctx, bearer) {
+		return &Registrant{Admin: true}, nil
+	}
+
+	parts := strings.SplitN(bearer, ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidInitialAccessToken
+	}
+	t, err := tokenModel.FindOne(ctx, parts[0])
+	if err == model.ErrNotFound {
+		return nil, ErrInvalidInitialAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !VerifyClientSecret(t.TokenHash, parts[1]) || !t.Usable(time.Now()) {
+		return nil, ErrInvalidInitialAccessToken
+	}
+
+	return &Registrant{token: t, tokenModel: tokenModel, requireApproval: requireApproval}, nil
+}
+
+// ClientStatus 返回新注册客户端的状态
+func (r *Registrant) ClientStatus() string {
+	if !r.Admin && r.requireApproval {
+		return model.ClientStatusPending
+	}
+	return model.ClientStatusActive
+}
+
+// AllowsScope 只有管理员可以注册带admin权限的客户端
+func (r *Registrant) AllowsScope(scopes Scopes, scope string) bool {
+	return r.Admin || !scopes.Contains(scope, AdminScope)
+}
+
+// Consume 注册成功前使用一次初始访问令牌，并发注册时以数据库的原子更新为准
+func (r *Registrant) Consume(ctx context.Context) error {
+	if r.Admin {
+		return nil
+	}
+	ok, err := r.tokenModel.Consume(ctx, r.token.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidInitialAccessToken
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"context"
+
+	"oauth2-server/internal/model"
+)
+
+// DeleteClient 删除客户端，并级联删除其授权记录和已签发的令牌
+func DeleteClient(ctx context.Context, clientModel model.ClientModel, authorizationModel model.AuthorizationModel,
+	redisStore *RedisStore, clientID string) error {
+	// 自动批准和密码模式签发的令牌没有授权记录，以Redis中的用户索引为准，
+	// 并合并授权记录中的用户，兼容建立索引之前签发的令牌
+	userIDs, err := redisStore.GrantUserIDs(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	authorized, err := authorizationModel.FindUserIDsByClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	for _, userID := range append(authorized, "") {
+		if !contains(userIDs, userID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	// 吊销用户授权的令牌，以及客户端凭证模式签发的令牌
+	for _, userID := range userIDs {
+		if err := redisStore.RevokeGrant(ctx, userID, clientID); err != nil {
+			return err
+		}
+	}
+	if err := redisStore.DeleteGrantUserIDs(ctx, clientID); err != nil {
+		return err
+	}
+
+	if err := authorizationModel.DeleteByClient(ctx, clientID); err != nil {
+		return err
+	}
+	return clientModel.Delete(ctx, clientID)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"oauth2-server/internal/model"
+)
+
+// fakeClientModel 只实现Delete
+type fakeClientModel struct {
+	model.ClientModel
+	deleted []string
+}
+
+func (m *fakeClientModel) Delete(_ context.Context, id string) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+// fakeAuthorizationModel 没有任何授权记录
+type fakeAuthorizationModel struct {
+	model.AuthorizationModel
+}
+
+func (fakeAuthorizationModel) FindUserIDsByClient(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func (fakeAuthorizationModel) DeleteByClient(context.Context, string) error {
+	return nil
+}
+
+func TestDeleteClientRevokesTokensWithoutAuthorization(t *testing.T) {
+	rs := NewRedisStore(*redis.New(miniredis.RunT(t).Addr()))
+	ts := NewTokenStore(rs, time.Hour)
+	ctx := context.Background()
+
+	// 自动批准和密码模式签发的令牌都不写入授权记录
+	issue := func(userID, access, refresh string) {
+		token := models.NewToken()
+		token.ClientID, token.UserID, token.Scope = "auto_client", userID, "userid"
+		token.Access, token.AccessCreateAt, token.AccessExpiresIn = access, time.Now(), time.Minute
+		token.Refresh, token.RefreshCreateAt, token.RefreshExpiresIn = refresh, time.Now(), time.Hour
+		if err := ts.Create(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	issue("alice", "access-alice", "refresh-alice")
+	issue("bob", "access-bob", "refresh-bob")
+	issue("", "access-service", "")
+
+	// 其他客户端的令牌不受影响
+	other := models.NewToken()
+	other.ClientID, other.UserID = "other_client", "alice"
+	other.Access, other.AccessCreateAt, other.AccessExpiresIn = "access-other", time.Now(), time.Minute
+	if err := ts.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	clientModel := &fakeClientModel{}
+	if err := DeleteClient(ctx, clientModel, fakeAuthorizationModel{}, rs, "auto_client"); err != nil {
+		t.Fatal(err)
+	}
+	if len(clientModel.deleted) != 1 || clientModel.deleted[0] != "auto_client" {
+		t.Errorf("deleted clients = %v, want [auto_client]", clientModel.deleted)
+	}
+
+	for _, access := range []string{"access-alice", "access-bob", "access-service"} {
+		if ti, err := ts.GetByAccess(ctx, access); err != nil || ti != nil {
+			t.Errorf("GetByAccess(%q) = %v, %v, want nil", access, ti, err)
+		}
+	}
+	for _, refresh := range []string{"refresh-alice", "refresh-bob"} {
+		if ti, err := ts.GetByRefresh(ctx, refresh); err != nil || ti != nil {
+			t.Errorf("GetByRefresh(%q) = %v, %v, want nil", refresh, ti, err)
+		}
+	}
+	if ti, _ := ts.GetByAccess(ctx, "access-other"); ti == nil {
+		t.Errorf("token of another client was revoked")
+	}
+	if userIDs, _ := rs.GrantUserIDs(ctx, "auto_client"); len(userIDs) != 0 {
+		t.Errorf("GrantUserIDs() after delete = %v, want empty", userIDs)
+	}
+}
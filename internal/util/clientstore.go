@@ -0,0 +1,93 @@
+package util
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/errors"
+	"github.com/zeromicro/go-zero/core/collection"
+
+	"oauth2-server/internal/config"
+	"oauth2-server/internal/model"
+)
+
+// ClientStore 基于MySQL的客户端存储，同时实现model.ClientModel和go-oauth2的oauth2.ClientStore。
+// FindByID和GetByID使用容量有限的进程内缓存，经由本存储修改或删除客户端时立即失效，
+// 其他进程的修改最迟在缓存过期后生效
+type ClientStore struct {
+	model.ClientModel
+	cache *collection.Cache
+}
+
+// NewClientStore 创建带缓存的客户端存储
+func NewClientStore(clientModel model.ClientModel, c config.ClientCacheConf) (*ClientStore, error) {
+	cache, err := collection.NewCache(time.Duration(c.Expire)*time.Second,
+		collection.WithLimit(c.Limit), collection.WithName("client"))
+	if err != nil {
+		return nil, err
+	}
+	return &ClientStore{ClientModel: clientModel, cache: cache}, nil
+}
+
+// MustNewClientStore 创建带缓存的客户端存储，失败时退出
+func MustNewClientStore(clientModel model.ClientModel, c config.ClientCacheConf) *ClientStore {
+	s, err := NewClientStore(clientModel, c)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// GetByID 实现oauth2.ClientStore，不存在或未启用的客户端返回invalid_client
+func (s *ClientStore) GetByID(ctx context.Context, id string) (oauth2.ClientInfo, error) {
+	client, err := s.FindByID(ctx, id)
+	if err == model.ErrNotFound {
+		return nil, errors.ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive() {
+		return nil, errors.ErrInvalidClient
+	}
+	return NewOAuthClient(client), nil
+}
+
+// FindByID 优先从缓存读取客户端，不缓存不存在的客户端，新注册的客户端可以立即使用。
+// 返回的是副本，调用方修改不会影响缓存
+func (s *ClientStore) FindByID(ctx context.Context, id string) (*model.Client, error) {
+	v, err := s.cache.Take(id, func() (any, error) {
+		client, err := s.ClientModel.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return *client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	client := v.(model.Client)
+	return &client, nil
+}
+
+func (s *ClientStore) Insert(ctx context.Context, data *model.Client) (sql.Result, error) {
+	defer s.Invalidate(data.ID)
+	return s.ClientModel.Insert(ctx, data)
+}
+
+func (s *ClientStore) Update(ctx context.Context, data *model.Client) error {
+	defer s.Invalidate(data.ID)
+	return s.ClientModel.Update(ctx, data)
+}
+
+func (s *ClientStore) Delete(ctx context.Context, id string) error {
+	defer s.Invalidate(id)
+	return s.ClientModel.Delete(ctx, id)
+}
+
+// Invalidate 使客户端缓存失效，直接修改数据库后需要调用
+func (s *ClientStore) Invalidate(id string) {
+	s.cache.Del(id)
+}
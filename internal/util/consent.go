@@ -0,0 +1,51 @@
+package util
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"oauth2-server/internal/model"
+)
+
+// 授权页面中用户的选择
+const (
+	ConsentApprove = "approve"
+	ConsentReject  = "reject"
+)
+
+// ScopeDescription 授权页面展示的权限说明
+type ScopeDescription struct {
+	Name        string
+	Description string
+	Sensitive   bool // 敏感权限在页面上突出显示
+}
+
+// ConsentPage 授权页面数据
+type ConsentPage struct {
+	ClientName string             // 客户端名称
+	Scopes     []ScopeDescription // 请求的权限范围
+	Token      string             // 防跨站请求伪造的表单令牌
+}
+
+// HasConsent 判断用户在有效期内批准过的授权是否已覆盖请求的全部权限
+func HasConsent(ctx context.Context, authorizationModel model.AuthorizationModel, clientID, userID, scope string, expire int64) (bool, error) {
+	since := time.Now().Add(-time.Duration(expire) * time.Second)
+	grants, err := authorizationModel.FindApproved(ctx, clientID, userID, since)
+	if err != nil || len(grants) == 0 {
+		return false, err
+	}
+
+	granted := make(map[string]bool)
+	for _, grant := range grants {
+		for _, s := range strings.Fields(grant.Scope) {
+			granted[s] = true
+		}
+	}
+	for _, s := range strings.Fields(scope) {
+		if !granted[s] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"oauth2-server/internal/model"
+)
+
+// Grant 用户已授权的客户端
+type Grant struct {
+	ClientID       string    `json:"client_id"`        // 客户端ID
+	ClientName     string    `json:"client_name"`      // 客户端名称
+	Scope          string    `json:"scope"`            // 已授权的权限范围
+	FirstGrantedAt time.Time `json:"first_granted_at"` // 首次授权时间
+	LastGrantedAt  time.Time `json:"last_granted_at"`  // 最近授权时间
+}
+
+// GrantsPage 授权管理页面数据
+type GrantsPage struct {
+	Grants []*Grant
+	Token  string // 防跨站请求伪造的表单令牌
+}
+
+// ListGrants 按客户端汇总用户当前有效的授权记录
+func ListGrants(ctx context.Context, authorizationModel model.AuthorizationModel, clientModel model.ClientModel,
+	userID string) ([]*Grant, error) {
+	auths, err := authorizationModel.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]*Grant, 0)
+	byClient := make(map[string]*Grant)
+	for _, auth := range auths {
+		if auth.Status != model.AuthorizationStatusApproved {
+			continue
+		}
+
+		grant, ok := byClient[auth.ClientID]
+		if !ok {
+			grant = &Grant{
+				ClientID:       auth.ClientID,
+				ClientName:     auth.ClientID,
+				FirstGrantedAt: auth.CreatedAt,
+			}
+			if client, err := clientModel.FindByID(ctx, auth.ClientID); err == nil {
+				grant.ClientName = client.Name
+			}
+			byClient[auth.ClientID] = grant
+			grants = append(grants, grant)
+		}
+
+		grant.Scope = mergeScope(grant.Scope, auth.Scope)
+		grant.LastGrantedAt = auth.CreatedAt
+	}
+
+	return grants, nil
+}
+
+// RevokeGrant 撤销用户对客户端的授权，并吊销已签发给该客户端的令牌
+func RevokeGrant(ctx context.Context, authorizationModel model.AuthorizationModel, redisStore *RedisStore,
+	userID, clientID string) error {
+	if err := authorizationModel.Revoke(ctx, clientID, userID); err != nil {
+		return err
+	}
+	return redisStore.RevokeGrant(ctx, userID, clientID)
+}
+
+// mergeScope 合并两个以空格分隔的scope，保持首次出现的顺序
+func mergeScope(a, b string) string {
+	scopes := strings.Fields(a)
+	for _, s := range strings.Fields(b) {
+		found := false
+		for _, existing := range scopes {
+			if existing == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			scopes = append(scopes, s)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
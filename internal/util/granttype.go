@@ -0,0 +1,46 @@
+package util
+
+import (
+	"errors"
+	"strings"
+
+	"oauth2-server/internal/model"
+
+	oauth2errors "github.com/go-oauth2/oauth2/v4/errors"
+)
+
+var (
+	// ErrUnauthorizedClient 客户端未被允许使用该授权模式或响应类型
+	ErrUnauthorizedClient = oauth2errors.ErrUnauthorizedClient
+	// ErrUnsupportedGrantType 注册了服务端不支持的授权模式
+	ErrUnsupportedGrantType = oauth2errors.ErrUnsupportedGrantType
+	// ErrPublicClientCredentials 公开客户端注册了客户端凭证模式
+	ErrPublicClientCredentials = errors.New("client_credentials requires client authentication")
+)
+
+// NormalizeGrantTypes 解析以空格分隔的授权模式并去除重复，每一项都必须在supported中，且至少包含一项
+func NormalizeGrantTypes(grantType string, supported []string) ([]string, error) {
+	var grantTypes []string
+	for _, gt := range strings.Fields(grantType) {
+		if contains(grantTypes, gt) {
+			continue
+		}
+		if !contains(supported, gt) {
+			return nil, ErrUnsupportedGrantType
+		}
+		grantTypes = append(grantTypes, gt)
+	}
+
+	if len(grantTypes) == 0 {
+		return nil, ErrUnsupportedGrantType
+	}
+	return grantTypes, nil
+}
+
+// CheckPublicGrantTypes 公开客户端无法保护密钥，不能注册客户端凭证模式
+func CheckPublicGrantTypes(client *model.Client, grantTypes []string) error {
+	if client.IsPublic() && contains(grantTypes, "client_credentials") {
+		return ErrPublicClientCredentials
+	}
+	return nil
+}
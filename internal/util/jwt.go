@@ -9,33 +9,71 @@ import (
 type JwtClaims struct {
 	UserID   string `json:"user_id"`
 	ClientID string `json:"client_id"`
-	Scope    string `json:"scope"`
+	Scope    string `json:"scope,omitempty"`
+	Nonce    string `json:"nonce,omitempty"`     // ID Token的nonce
+	AuthTime int64  `json:"auth_time,omitempty"` // ID Token的用户认证时间
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT token
-func GenerateToken(userID, clientID, scope, secret string, expire int64) (string, error) {
+// GenerateToken 使用密钥集合中当前生效的密钥生成JWT token
+func GenerateToken(userID, clientID, scope string, keys *KeySet, expire int64) (string, error) {
+	key, err := keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
 	now := time.Now()
 	claims := JwtClaims{
 		UserID:   userID,
 		ClientID: clientID,
 		Scope:    scope,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{clientID},
 			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(expire) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return key.sign(claims)
+}
+
+// OpenIDEnabled 是否支持OpenID Connect：需要配置Issuer，并且当前使用非对称密钥签名。
+// 使用共享密钥签名的ID Token客户端无法验证，iss也不能取自客户端可以伪造的请求头
+func OpenIDEnabled(issuer string, keys *KeySet) bool {
+	key, err := keys.SigningKey()
+	return issuer != "" && err == nil && !key.IsSymmetric()
+}
+
+// GenerateIDToken 生成OpenID Connect ID Token
+func GenerateIDToken(issuer, userID, clientID, nonce string, authTime time.Time, keys *KeySet, expire int64) (string, error) {
+	key, err := keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := JwtClaims{
+		UserID:   userID,
+		ClientID: clientID,
+		Nonce:    nonce,
+		AuthTime: authTime.Unix(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(expire) * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	return key.sign(claims)
 }
 
-// ParseToken 解析JWT token
-func ParseToken(tokenString, secret string) (*JwtClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JwtClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+// ParseToken 解析JWT token，根据kid选择验证密钥，且只接受与该密钥一致的算法
+func ParseToken(tokenString string, keys *KeySet) (*JwtClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JwtClaims{}, keys.keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,402 @@
+package util
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+
+	"oauth2-server/internal/config"
+)
+
+var (
+	// ErrNoSigningKey 当前没有可用于签名的密钥
+	ErrNoSigningKey = errors.New("no active signing key")
+	// ErrRotationUnsupported 使用共享密钥签名时不能轮换，共享密钥既不能发布也不能在实例间自动生成；
+	// 未配置KeyEncryptionKey时不能轮换，轮换生成的私钥只能加密后保存到Redis
+	ErrRotationUnsupported = errors.New("signing key rotation requires an asymmetric PrivateKeyFile and a KeyEncryptionKey")
+	// ErrInvalidKeyEncryptionKey KeyEncryptionKey不是base64编码的32字节密钥
+	ErrInvalidKeyEncryptionKey = errors.New("KeyEncryptionKey must be a base64-encoded 32-byte key")
+	// ErrInvalidSealedKey Redis中保存的私钥无法解密
+	ErrInvalidSealedKey = errors.New("invalid sealed signing key")
+)
+
+// keySyncInterval 从Redis同步其他实例轮换生成的密钥的间隔，新密钥至少在此之后才启用签名，
+// 保证其他实例已经同步，能够验证新密钥签发的令牌
+const keySyncInterval = time.Minute
+
+// KeySet 签名密钥集合
+// 同一时刻只有一个密钥用于签名，被接替的旧密钥在重叠期内仍可用于验证，
+// 尚未启用的新密钥会提前发布到JWKS，便于资源服务器预先缓存。
+// 轮换生成的密钥保存在Redis中，所有实例（包括go-zero服务和go-oauth2服务）共用，重启后仍然有效。
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    []*SigningKey // 按NotBefore升序排列
+	overlap time.Duration // 旧密钥被接替后继续用于验证的时长，应不小于访问令牌有效期
+
+	store  *RedisStore // 保存轮换生成的密钥，为nil时不能轮换
+	method string      // 轮换生成新密钥使用的签名算法
+	aead   cipher.AEAD // 加密保存到Redis的私钥
+}
+
+// storedKey Redis中保存的签名密钥
+type storedKey struct {
+	Method     string `json:"alg"`
+	PrivateKey string `json:"key"` // AES-GCM加密的PKCS#8 PEM，nonce在前，base64编码
+	NotBefore  int64  `json:"nbf"` // Unix秒
+}
+
+// NewKeySet 创建签名密钥集合
+func NewKeySet(overlap time.Duration, keys ...*SigningKey) *KeySet {
+	s := &KeySet{overlap: overlap}
+	for _, key := range keys {
+		s.Add(key)
+	}
+	return s
+}
+
+// MustNewKeySet 根据配置创建签名密钥集合。使用非对称密钥并配置了KeyEncryptionKey时从Redis加载轮换生成的密钥并定期同步，
+// 配置了自动轮换时按间隔生成新密钥；使用共享密钥或未配置KeyEncryptionKey时不能配置自动轮换
+func MustNewKeySet(c config.AuthConf, store *RedisStore) *KeySet {
+	if c.RotateInterval > 0 && (c.PrivateKeyFile == "" || c.KeyEncryptionKey == "") {
+		panic(ErrRotationUnsupported)
+	}
+
+	primary := NewHMACSigningKey(c.AccessSecret)
+	if c.PrivateKeyFile != "" {
+		key, err := LoadSigningKey(c.SigningMethod, c.PrivateKeyFile, c.KeyID)
+		if err != nil {
+			panic(err)
+		}
+		primary = key
+	}
+
+	s := NewKeySet(time.Duration(c.AccessExpire)*time.Second, primary)
+	for _, kc := range c.Keys {
+		key, err := LoadSigningKey(kc.SigningMethod, kc.PrivateKeyFile, kc.KeyID)
+		if err != nil {
+			panic(err)
+		}
+		if key.NotBefore, err = parseKeyTime(kc.NotBefore); err != nil {
+			panic(err)
+		}
+		if key.RetireAfter, err = parseKeyTime(kc.RetireAfter); err != nil {
+			panic(err)
+		}
+		s.Add(key)
+	}
+
+	if c.PrivateKeyFile != "" && c.KeyEncryptionKey != "" && store != nil {
+		aead, err := newKeyCipher(c.KeyEncryptionKey)
+		if err != nil {
+			panic(err)
+		}
+		s.store, s.method, s.aead = store, c.SigningMethod, aead
+		if err := s.Sync(context.Background()); err != nil {
+			panic(err)
+		}
+		s.StartRotation(time.Duration(c.RotateInterval)*time.Second, time.Duration(c.RotatePrepublish)*time.Second)
+	}
+
+	return s
+}
+
+// Add 加入密钥，kid已存在时忽略
+func (s *KeySet) Add(key *SigningKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if key.ID != "" && k.ID == key.ID {
+			return
+		}
+	}
+	s.keys = append(s.keys, key)
+	sort.SliceStable(s.keys, func(i, j int) bool {
+		return s.keys[i].NotBefore.Before(s.keys[j].NotBefore)
+	})
+}
+
+// Rotate 按需轮换：生成新密钥并加密保存到Redis，新密钥在activateAt开始接替签名，此前只发布不签名。
+// activateAt早于keySyncInterval之后时推迟到keySyncInterval之后，避免尚未同步的实例拒绝新密钥签发的令牌
+func (s *KeySet) Rotate(ctx context.Context, activateAt time.Time) (*SigningKey, error) {
+	if s.store == nil {
+		return nil, ErrRotationUnsupported
+	}
+	if earliest := time.Now().Add(keySyncInterval); activateAt.Before(earliest) {
+		activateAt = earliest
+	}
+
+	key, err := GenerateSigningKey(s.method)
+	if err != nil {
+		return nil, err
+	}
+	key.NotBefore = activateAt
+
+	sealed, err := s.sealKey(key.ID, key.SignedKey)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.StoreSigningKey(ctx, key.ID, storedKey{
+		Method:     key.Method.Alg(),
+		PrivateKey: sealed,
+		NotBefore:  activateAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Add(key)
+	return key, s.prune(ctx)
+}
+
+// Sync 加载其他实例轮换生成的密钥，并清理已停止验证的密钥
+func (s *KeySet) Sync(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	stored, err := s.store.SigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for kid, raw := range stored {
+		if s.has(kid) {
+			continue
+		}
+
+		var sk storedKey
+		if err := json.Unmarshal([]byte(raw), &sk); err != nil {
+			logx.Errorf("invalid signing key %s: %v", kid, err)
+			continue
+		}
+		pem, err := s.openKey(kid, sk.PrivateKey)
+		if err != nil {
+			logx.Errorf("invalid signing key %s: %v", kid, err)
+			continue
+		}
+		key, err := ParseSigningKey(sk.Method, pem, kid)
+		if err != nil {
+			logx.Errorf("invalid signing key %s: %v", kid, err)
+			continue
+		}
+		key.NotBefore = time.Unix(sk.NotBefore, 0)
+		s.Add(key)
+	}
+
+	return s.prune(ctx)
+}
+
+// StartRotation 定期同步共用的密钥。interval大于0时，最新的密钥启用满interval后由下一个密钥接替，
+// 下一个密钥提前prepublish生成并发布，多个实例通过Redis锁保证只有一个实例生成
+func (s *KeySet) StartRotation(interval, prepublish time.Duration) {
+	started := time.Now()
+	threading.GoSafe(func() {
+		ticker := time.NewTicker(keySyncInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx := context.Background()
+			if err := s.Sync(ctx); err != nil {
+				logx.Errorf("sync signing keys failed: %v", err)
+				continue
+			}
+			if interval <= 0 || time.Now().Before(s.nextRotation(started, interval, prepublish)) {
+				continue
+			}
+
+			locked, err := s.store.LockKeyRotation(ctx, keySyncInterval)
+			if err != nil {
+				logx.Errorf("lock signing key rotation failed: %v", err)
+				continue
+			}
+			if !locked {
+				continue
+			}
+
+			key, err := s.Rotate(ctx, time.Now().Add(prepublish))
+			if err != nil {
+				logx.Errorf("rotate signing key failed: %v", err)
+				continue
+			}
+			logx.Infof("signing key %s scheduled, active after %s", key.ID, key.NotBefore.Format(time.RFC3339))
+		}
+	})
+}
+
+// nextRotation 计算生成下一个密钥的时间，最新的密钥没有启用时间时从进程启动开始计算
+func (s *KeySet) nextRotation(started time.Time, interval, prepublish time.Duration) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	latest := started
+	if n := len(s.keys); n > 0 && !s.keys[n-1].NotBefore.IsZero() {
+		latest = s.keys[n-1].NotBefore
+	}
+	return latest.Add(interval - prepublish)
+}
+
+// SigningKey 返回当前用于签名的密钥
+func (s *KeySet) SigningKey() (*SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		key := s.keys[i]
+		if !key.NotBefore.After(now) && now.Before(s.retireAt(i)) {
+			return key, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// VerificationKey 根据kid查找仍可用于验证的密钥
+func (s *KeySet) VerificationKey(kid string) (*SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for i, key := range s.keys {
+		if key.ID == kid && !key.NotBefore.After(now) && now.Before(s.retireAt(i)) {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// PublishedKeys 返回需要发布到JWKS的密钥，包括尚未启用的新密钥
+func (s *KeySet) PublishedKeys() []*SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var keys []*SigningKey
+	for i, key := range s.keys {
+		if now.Before(s.retireAt(i)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Prune 清理已经停止验证的密钥，返回被清理的密钥
+func (s *KeySet) Prune() []*SigningKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var keys, retired []*SigningKey
+	for i, key := range s.keys {
+		if now.Before(s.retireAt(i)) {
+			keys = append(keys, key)
+		} else {
+			retired = append(retired, key)
+		}
+	}
+	s.keys = keys
+	return retired
+}
+
+// prune 清理已经停止验证的密钥，并从Redis中删除
+func (s *KeySet) prune(ctx context.Context) error {
+	for _, key := range s.Prune() {
+		if err := s.store.DeleteSigningKey(ctx, key.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *KeySet) has(kid string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return true
+		}
+	}
+	return false
+}
+
+// retireAt 计算第i个密钥停止用于验证的时间：
+// 显式配置了RetireAfter时以其为准，否则为接替密钥启用后再经过重叠期
+func (s *KeySet) retireAt(i int) time.Time {
+	key := s.keys[i]
+	if !key.RetireAfter.IsZero() {
+		return key.RetireAfter
+	}
+	if i+1 < len(s.keys) {
+		return s.keys[i+1].NotBefore.Add(s.overlap)
+	}
+	return maxKeyTime
+}
+
+// keyFunc 供jwt解析时根据kid选择验证密钥
+func (s *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.VerificationKey(kid)
+	if !ok {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.verifyKey, nil
+}
+
+// sealKey 加密私钥，kid作为附加数据，密文不能被挪到其他kid下使用
+func (s *KeySet) sealKey(kid string, pem []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, pem, []byte(kid))), nil
+}
+
+// openKey 解密sealKey加密的私钥
+func (s *KeySet) openKey(kid, sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, ErrInvalidSealedKey
+	}
+	n := s.aead.NonceSize()
+	pem, err := s.aead.Open(nil, data[:n], data[n:], []byte(kid))
+	if err != nil {
+		return nil, ErrInvalidSealedKey
+	}
+	return pem, nil
+}
+
+// newKeyCipher 根据base64编码的32字节密钥创建AES-256-GCM加密器
+func newKeyCipher(encoded string) (cipher.AEAD, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidKeyEncryptionKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var maxKeyTime = time.Unix(1<<62, 0)
+
+func parseKeyTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
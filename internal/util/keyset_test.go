@@ -0,0 +1,203 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"oauth2-server/internal/config"
+)
+
+func TestKeySetSigningKey(t *testing.T) {
+	now := time.Now()
+	key := func(id string, notBefore, retireAfter time.Time) *SigningKey {
+		return &SigningKey{ID: id, NotBefore: notBefore, RetireAfter: retireAfter}
+	}
+
+	tests := []struct {
+		name    string
+		keys    []*SigningKey
+		want    string
+		wantErr bool
+	}{
+		{"只有一个密钥", []*SigningKey{key("a", time.Time{}, time.Time{})}, "a", false},
+		{"新密钥已启用", []*SigningKey{key("a", time.Time{}, time.Time{}), key("b", now.Add(-time.Minute), time.Time{})}, "b", false},
+		{"新密钥尚未启用", []*SigningKey{key("a", time.Time{}, time.Time{}), key("b", now.Add(time.Hour), time.Time{})}, "a", false},
+		{"按启用时间而不是加入顺序选择", []*SigningKey{key("b", now.Add(-time.Minute), time.Time{}), key("a", now.Add(-time.Hour), time.Time{})}, "b", false},
+		{"最新的密钥已显式停用", []*SigningKey{key("a", time.Time{}, time.Time{}), key("b", now.Add(-time.Minute), now.Add(-time.Second))}, "a", false},
+		{"全部密钥尚未启用", []*SigningKey{key("a", now.Add(time.Hour), time.Time{})}, "", true},
+		{"没有密钥", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewKeySet(time.Hour, tt.keys...)
+			got, err := s.SigningKey()
+			if tt.wantErr {
+				if err != ErrNoSigningKey {
+					t.Errorf("SigningKey() = %v, %v, want ErrNoSigningKey", got, err)
+				}
+				return
+			}
+			if err != nil || got.ID != tt.want {
+				t.Errorf("SigningKey() = %v, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeySetRetirement(t *testing.T) {
+	now := time.Now()
+	overlap := time.Hour
+	s := NewKeySet(overlap,
+		// 48小时前被a接替，已超过重叠期
+		&SigningKey{ID: "old", NotBefore: now.Add(-72 * time.Hour)},
+		// 30分钟前被b接替，仍在重叠期内
+		&SigningKey{ID: "a", NotBefore: now.Add(-48 * time.Hour)},
+		// 当前的签名密钥
+		&SigningKey{ID: "b", NotBefore: now.Add(-30 * time.Minute)},
+		// 提前发布，尚未启用
+		&SigningKey{ID: "next", NotBefore: now.Add(time.Hour)},
+	)
+	tests := []struct {
+		kid        string
+		verifiable bool
+		published  bool
+	}{
+		{"old", false, false},
+		{"a", true, true},
+		{"b", true, true},
+		{"next", false, true},
+		{"unknown", false, false},
+	}
+
+	published := map[string]bool{}
+	for _, key := range s.PublishedKeys() {
+		published[key.ID] = true
+	}
+	for _, tt := range tests {
+		t.Run(tt.kid, func(t *testing.T) {
+			if _, ok := s.VerificationKey(tt.kid); ok != tt.verifiable {
+				t.Errorf("VerificationKey(%q) = %v, want %v", tt.kid, ok, tt.verifiable)
+			}
+			if published[tt.kid] != tt.published {
+				t.Errorf("PublishedKeys() contains %q = %v, want %v", tt.kid, published[tt.kid], tt.published)
+			}
+		})
+	}
+
+	if key, err := s.SigningKey(); err != nil || key.ID != "b" {
+		t.Errorf("SigningKey() = %v, %v, want b", key, err)
+	}
+
+	retired := s.Prune()
+	if len(retired) != 1 || retired[0].ID != "old" {
+		t.Errorf("Prune() = %v, want [old]", retired)
+	}
+	if s.has("old") || !s.has("a") {
+		t.Errorf("Prune() kept the wrong keys")
+	}
+}
+
+func TestKeySetAddIgnoresDuplicateKeyID(t *testing.T) {
+	s := NewKeySet(time.Hour, &SigningKey{ID: "a"})
+	s.Add(&SigningKey{ID: "a", NotBefore: time.Now().Add(time.Hour)})
+	if n := len(s.PublishedKeys()); n != 1 {
+		t.Errorf("PublishedKeys() has %d keys, want 1", n)
+	}
+}
+
+func TestMustNewKeySetRejectsRotationWithSharedSecret(t *testing.T) {
+	defer func() {
+		if r := recover(); r != ErrRotationUnsupported {
+			t.Errorf("MustNewKeySet() panic = %v, want ErrRotationUnsupported", r)
+		}
+	}()
+	MustNewKeySet(config.AuthConf{AccessSecret: "secret", AccessExpire: 3600, RotateInterval: 86400}, nil)
+}
+
+func TestKeySetRotateWithoutStore(t *testing.T) {
+	s := NewKeySet(time.Hour, NewHMACSigningKey("secret"))
+	if _, err := s.Rotate(context.Background(), time.Now()); err != ErrRotationUnsupported {
+		t.Errorf("Rotate() error = %v, want ErrRotationUnsupported", err)
+	}
+}
+
+// newTestRotatingKeySet 创建使用Redis保存轮换密钥的集合，kek为base64编码的加密密钥
+func newTestRotatingKeySet(t *testing.T, store *RedisStore, primary *SigningKey, kek string) *KeySet {
+	aead, err := newKeyCipher(kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewKeySet(time.Hour, primary)
+	s.store, s.method, s.aead = store, "ES256", aead
+	return s
+}
+
+func TestKeySetRotateSharesKeysThroughRedis(t *testing.T) {
+	store := NewRedisStore(*redis.New(miniredis.RunT(t).Addr()))
+	ctx := context.Background()
+	kek := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	primary, err := GenerateSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestRotatingKeySet(t, store, primary, kek)
+	b := newTestRotatingKeySet(t, store, primary, kek)
+
+	// 要求立即启用时仍等待其他实例同步
+	rotated, err := a.Rotate(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.NotBefore.Before(time.Now().Add(keySyncInterval - time.Second)) {
+		t.Errorf("Rotate() NotBefore = %v, want at least %v later", rotated.NotBefore, keySyncInterval)
+	}
+	if key, err := a.SigningKey(); err != nil || key.ID != primary.ID {
+		t.Errorf("SigningKey() right after Rotate = %v, %v, want %s", key, err, primary.ID)
+	}
+
+	// Redis中只保存加密后的私钥
+	stored, err := store.SigningKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw := stored[rotated.ID]; raw == "" || strings.Contains(raw, "PRIVATE KEY") {
+		t.Errorf("stored signing key = %q, want sealed private key", raw)
+	}
+
+	// 其他实例同步后提前发布新密钥
+	if err := b.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !b.has(rotated.ID) {
+		t.Fatalf("Sync() did not load %s", rotated.ID)
+	}
+	synced := b.keys[len(b.keys)-1]
+	if synced.ID != rotated.ID || !synced.NotBefore.Equal(rotated.NotBefore.Truncate(time.Second)) {
+		t.Errorf("synced key = %s at %v, want %s at %v", synced.ID, synced.NotBefore, rotated.ID, rotated.NotBefore)
+	}
+
+	// 加密密钥不同的实例无法加载
+	c := newTestRotatingKeySet(t, store, primary, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)))
+	if err := c.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.has(rotated.ID) {
+		t.Errorf("Sync() loaded %s with a different KeyEncryptionKey", rotated.ID)
+	}
+}
+
+func TestNewKeyCipherRejectsInvalidKey(t *testing.T) {
+	for _, kek := range []string{"", "not base64", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		if _, err := newKeyCipher(kek); err != ErrInvalidKeyEncryptionKey {
+			t.Errorf("newKeyCipher(%q) error = %v, want ErrInvalidKeyEncryptionKey", kek, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// 授权服务器端点路径，go-zero路由和oauth2.go共用，元数据文档据此生成
+const (
+	ClientRegisterPath      = "/api/client/register"
+	ClientPath              = "/api/client/:id"
+	ClientSecretPath        = "/api/client/:id/secret"
+	ClientsPath             = "/api/clients"
+	ClientApprovePath       = "/api/client/:id/approve"
+	InitialTokensPath       = "/api/initial-access-tokens"
+	InitialTokenPath        = "/api/initial-access-tokens/:id"
+	SigningKeyRotatePath    = "/api/signing-keys/rotate"
+	DynamicRegisterPath     = "/oauth/register"
+	DynamicClientPath       = "/oauth/register/:client_id"
+	AuthorizePath           = "/oauth/authorize"
+	LoginPath               = "/login"
+	ConsentPath             = "/auth"
+	GrantsPath              = "/grants"
+	GrantsAPIPath           = "/api/grants"
+	GrantAPIPath            = "/api/grants/:client_id"
+	TokenPath               = "/oauth/token"
+	RevokePath              = "/oauth/revoke"
+	IntrospectPath          = "/oauth/introspect"
+	UserInfoPath            = "/oauth/userinfo"
+	JwksPath                = "/.well-known/jwks.json"
+	OAuthMetadataPath       = "/.well-known/oauth-authorization-server"
+	OpenIDConfigurationPath = "/.well-known/openid-configuration"
+)
+
+// ServerMetadata 授权服务器元数据（RFC 8414 / OpenID Connect Discovery）
+type ServerMetadata struct {
+	Issuer                                    string   `json:"issuer"`
+	AuthorizationEndpoint                     string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                             string   `json:"token_endpoint,omitempty"`
+	RevocationEndpoint                        string   `json:"revocation_endpoint,omitempty"`
+	IntrospectionEndpoint                     string   `json:"introspection_endpoint,omitempty"`
+	UserinfoEndpoint                          string   `json:"userinfo_endpoint,omitempty"`
+	JwksURI                                   string   `json:"jwks_uri,omitempty"`
+	RegistrationEndpoint                      string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported                           []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported                    []string `json:"response_types_supported"`
+	GrantTypesSupported                       []string `json:"grant_types_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported         []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	RevocationEndpointAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported,omitempty"`
+	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported,omitempty"`
+	CodeChallengeMethodsSupported             []string `json:"code_challenge_methods_supported,omitempty"`
+	SubjectTypesSupported                     []string `json:"subject_types_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported          []string `json:"id_token_signing_alg_values_supported,omitempty"`
+}
+
+// MetadataOptions 生成元数据所需的服务器能力
+type MetadataOptions struct {
+	Issuer               string
+	Routes               []rest.Route // 已注册的路由，只有实际注册的端点才会发布
+	GrantTypes           []string
+	ResponseTypes        []string
+	Scopes               []string
+	CodeChallengeMethods []string
+	Keys                 []*SigningKey
+}
+
+// clientAuthMethods 令牌、吊销和内省端点支持的客户端认证方式
+var clientAuthMethods = []string{"client_secret_basic", "client_secret_post"}
+
+// NewServerMetadata 根据已注册的路由和配置生成元数据文档
+func NewServerMetadata(opts MetadataOptions) *ServerMetadata {
+	registered := make(map[string]bool)
+	for _, route := range opts.Routes {
+		registered[route.Path] = true
+	}
+	endpoint := func(path string) string {
+		if !registered[path] {
+			return ""
+		}
+		return opts.Issuer + path
+	}
+
+	// 只有注册了OpenID Connect发现端点时才发布OpenID Connect相关的元数据
+	openID := registered[OpenIDConfigurationPath]
+	scopes := opts.Scopes
+	if !openID {
+		scopes = nil
+		for _, scope := range opts.Scopes {
+			if scope != "openid" {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	md := &ServerMetadata{
+		Issuer:                        opts.Issuer,
+		AuthorizationEndpoint:         endpoint(AuthorizePath),
+		TokenEndpoint:                 endpoint(TokenPath),
+		RevocationEndpoint:            endpoint(RevokePath),
+		IntrospectionEndpoint:         endpoint(IntrospectPath),
+		UserinfoEndpoint:              endpoint(UserInfoPath),
+		JwksURI:                       endpoint(JwksPath),
+		RegistrationEndpoint:          endpoint(DynamicRegisterPath),
+		ScopesSupported:               scopes,
+		ResponseTypesSupported:        opts.ResponseTypes,
+		GrantTypesSupported:           opts.GrantTypes,
+		CodeChallengeMethodsSupported: opts.CodeChallengeMethods,
+	}
+	if md.TokenEndpoint != "" {
+		md.TokenEndpointAuthMethodsSupported = tokenEndpointAuthMethods
+	}
+	if md.RevocationEndpoint != "" {
+		md.RevocationEndpointAuthMethodsSupported = clientAuthMethods
+	}
+	if md.IntrospectionEndpoint != "" {
+		md.IntrospectionEndpointAuthMethodsSupported = clientAuthMethods
+	}
+
+	if !openID {
+		return md
+	}
+
+	// 签名算法取自当前发布的密钥，去重后保持顺序
+	md.SubjectTypesSupported = []string{"public"}
+	seen := make(map[string]bool)
+	for _, key := range opts.Keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			md.IDTokenSigningAlgValuesSupported = append(md.IDTokenSigningAlgValuesSupported, alg)
+		}
+	}
+
+	return md
+}
+
+// IssuerURL 返回配置的授权服务器标识，去掉末尾的斜杠。
+// 不根据请求的Host和X-Forwarded-Proto推断，这些请求头可以被客户端伪造
+func IssuerURL(configured string) string {
+	return strings.TrimRight(configured, "/")
+}
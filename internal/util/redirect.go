@@ -0,0 +1,141 @@
+package util
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	oauth2errors "github.com/go-oauth2/oauth2/v4/errors"
+)
+
+// ErrInvalidRedirectURI 回调地址不合法或未注册
+var ErrInvalidRedirectURI = oauth2errors.ErrInvalidRedirectURI
+
+// NormalizeRedirectURIs 合并单个回调地址和回调地址列表，逐个校验并去除重复
+func NormalizeRedirectURIs(single string, list []string) ([]string, error) {
+	var uris []string
+	for _, uri := range append([]string{single}, list...) {
+		if uri == "" || contains(uris, uri) {
+			continue
+		}
+		if err := ValidateRedirectURI(uri); err != nil {
+			return nil, err
+		}
+		uris = append(uris, uri)
+	}
+
+	if len(uris) == 0 {
+		return nil, ErrInvalidRedirectURI
+	}
+	return uris, nil
+}
+
+// ValidateRedirectURI 校验注册的回调地址：必须是不含片段的绝对地址，
+// 除http/https外只允许RFC 8252中反向域名形式的私有scheme（如com.example.app:/callback）
+func ValidateRedirectURI(uri string) error {
+	if strings.ContainsAny(uri, " \t\r\n") {
+		return ErrInvalidRedirectURI
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.Fragment != "" {
+		return ErrInvalidRedirectURI
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return ErrInvalidRedirectURI
+		}
+	default:
+		if !strings.Contains(u.Scheme, ".") {
+			return ErrInvalidRedirectURI
+		}
+	}
+	return nil
+}
+
+// MatchRedirectURI 判断请求的回调地址是否已注册。回调地址必须完全一致，
+// 只有注册地址为http回环IP时按RFC 8252允许任意端口
+func MatchRedirectURI(registered []string, requested string) bool {
+	for _, uri := range registered {
+		if uri == requested || matchLoopback(uri, requested) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveRedirectURI 确定本次授权使用的回调地址，请求未携带时只有注册了唯一地址才能省略
+func ResolveRedirectURI(registered []string, requested string) (string, error) {
+	if requested == "" {
+		if len(registered) != 1 {
+			return "", ErrInvalidRedirectURI
+		}
+		return registered[0], nil
+	}
+
+	if !MatchRedirectURI(registered, requested) {
+		return "", ErrInvalidRedirectURI
+	}
+	return requested, nil
+}
+
+// AuthorizeRedirect 在回调地址上追加授权结果参数，保留回调地址原有的查询参数
+func AuthorizeRedirect(redirectURI string, params url.Values) (string, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k, vs := range params {
+		for _, v := range vs {
+			if v != "" {
+				q.Add(k, v)
+			}
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// ValidateRedirectURIHandler 适配go-oauth2的回调地址校验，baseURI为以空格分隔的注册地址
+func ValidateRedirectURIHandler(baseURI, redirectURI string) error {
+	if !MatchRedirectURI(strings.Fields(baseURI), redirectURI) {
+		return oauth2errors.ErrInvalidRedirectURI
+	}
+	return nil
+}
+
+// matchLoopback 注册地址为http://127.0.0.1或http://[::1]时，忽略端口比较其余部分
+func matchLoopback(registered, requested string) bool {
+	r, err := url.Parse(registered)
+	if err != nil || r.Scheme != "http" || !isLoopbackIP(r.Hostname()) {
+		return false
+	}
+
+	u, err := url.Parse(requested)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == r.Scheme &&
+		u.Hostname() == r.Hostname() &&
+		u.EscapedPath() == r.EscapedPath() &&
+		u.RawQuery == r.RawQuery &&
+		u.Fragment == "" &&
+		u.User == nil
+}
+
+func isLoopbackIP(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
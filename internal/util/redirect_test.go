@@ -0,0 +1,94 @@
+package util
+
+import "testing"
+
+func TestValidateRedirectURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		wantErr bool
+	}{
+		{"https://app.example.com/callback", false},
+		{"http://localhost:3000/callback", false},
+		{"http://127.0.0.1/callback", false},
+		{"https://app.example.com/callback?tenant=1", false},
+		{"com.example.app:/callback", false},
+		{"", true},
+		{"/callback", true},
+		{"https:///callback", true},
+		{"https://app.example.com/callback#frag", true},
+		{"https://app.example.com/a b", true},
+		{"https://app.example.com/callback\n", true},
+		{"myapp:/callback", true},
+		{"javascript:alert(1)", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			if err := ValidateRedirectURI(tt.uri); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRedirectURI(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchRedirectURI(t *testing.T) {
+	registered := []string{
+		"https://app.example.com/callback",
+		"http://127.0.0.1/cb",
+		"http://[::1]:8080/cb?x=1",
+		"http://localhost:3000/callback",
+	}
+	tests := []struct {
+		name      string
+		requested string
+		want      bool
+	}{
+		{"完全一致", "https://app.example.com/callback", true},
+		{"路径前缀", "https://app.example.com/callback/evil", false},
+		{"多出查询参数", "https://app.example.com/callback?x=1", false},
+		{"大小写不同", "https://APP.example.com/callback", false},
+		{"scheme不同", "http://app.example.com/callback", false},
+		{"其他主机", "https://evil.example.com/callback", false},
+		{"IPv4回环地址任意端口", "http://127.0.0.1:51234/cb", true},
+		{"IPv4回环地址无端口", "http://127.0.0.1/cb", true},
+		{"IPv4回环地址路径不同", "http://127.0.0.1:51234/other", false},
+		{"IPv4回环地址携带片段", "http://127.0.0.1:51234/cb#x", false},
+		{"IPv4回环地址携带用户信息", "http://user@127.0.0.1:51234/cb", false},
+		{"IPv4回环地址使用https", "https://127.0.0.1:51234/cb", false},
+		{"IPv6回环地址任意端口", "http://[::1]:9999/cb?x=1", true},
+		{"IPv6回环地址查询参数不同", "http://[::1]:9999/cb?x=2", false},
+		{"localhost不放宽端口", "http://localhost:3001/callback", false},
+		{"回环地址不匹配其他IP", "http://127.0.0.2:51234/cb", false},
+		{"空地址", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchRedirectURI(registered, tt.requested); got != tt.want {
+				t.Errorf("MatchRedirectURI(%q) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRedirectURI(t *testing.T) {
+	tests := []struct {
+		name       string
+		registered []string
+		requested  string
+		want       string
+		wantErr    bool
+	}{
+		{"唯一地址时可省略", []string{"https://a.example.com/cb"}, "", "https://a.example.com/cb", false},
+		{"多个地址时不能省略", []string{"https://a.example.com/cb", "https://b.example.com/cb"}, "", "", true},
+		{"未注册地址时不能省略", nil, "", "", true},
+		{"返回请求中的回环地址", []string{"http://127.0.0.1/cb"}, "http://127.0.0.1:8000/cb", "http://127.0.0.1:8000/cb", false},
+		{"未注册的地址", []string{"https://a.example.com/cb"}, "https://b.example.com/cb", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveRedirectURI(tt.registered, tt.requested)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ResolveRedirectURI() = %q, %v, want %q, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
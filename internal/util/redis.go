@@ -89,3 +89,140 @@ func (rs *RedisStore) DeleteRefreshToken(ctx context.Context, refreshToken strin
 	_, err := rs.redis.DelCtx(ctx, key)
 	return err
 }
+
+// MarkRefreshTokenUsed 标记刷新令牌已被轮换并记录其所属的令牌族，返回false表示该令牌此前已被使用过
+func (rs *RedisStore) MarkRefreshTokenUsed(ctx context.Context, refreshToken, familyID string, expire time.Duration) (bool, error) {
+	key := "oauth:refresh_used:" + refreshToken
+	return rs.redis.SetnxExCtx(ctx, key, familyID, int(expire.Seconds()))
+}
+
+// UsedRefreshTokenFamily 返回已轮换的刷新令牌所属的令牌族，令牌未被使用过时返回空
+func (rs *RedisStore) UsedRefreshTokenFamily(ctx context.Context, refreshToken string) (string, error) {
+	key := "oauth:refresh_used:" + refreshToken
+	return rs.redis.GetCtx(ctx, key)
+}
+
+// ReleaseRefreshToken 撤销刷新令牌的已使用标记，签发新令牌失败时调用，使客户端可以重试
+func (rs *RedisStore) ReleaseRefreshToken(ctx context.Context, refreshToken string) error {
+	key := "oauth:refresh_used:" + refreshToken
+	_, err := rs.redis.DelCtx(ctx, key)
+	return err
+}
+
+// AddAccessTokenToFamily 将访问令牌加入令牌族
+func (rs *RedisStore) AddAccessTokenToFamily(ctx context.Context, familyID, token string, expire time.Duration) error {
+	return rs.addToFamily(ctx, familyID, "oauth:token:"+token, expire)
+}
+
+// AddRefreshTokenToFamily 将刷新令牌加入令牌族
+func (rs *RedisStore) AddRefreshTokenToFamily(ctx context.Context, familyID, refreshToken string, expire time.Duration) error {
+	return rs.addToFamily(ctx, familyID, "oauth:refresh:"+refreshToken, expire)
+}
+
+// RevokeFamily 吊销令牌族中的全部访问令牌和刷新令牌
+func (rs *RedisStore) RevokeFamily(ctx context.Context, familyID string) error {
+	familyKey := "oauth:family:" + familyID
+	keys, err := rs.redis.SmembersCtx(ctx, familyKey)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if _, err := rs.redis.DelCtx(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	_, err = rs.redis.DelCtx(ctx, familyKey)
+	return err
+}
+
+// AddFamilyToGrant 记录用户授权给客户端后签发的令牌族，并在客户端的用户索引中记录该用户。
+// 自动批准和密码模式不写入授权记录，删除客户端时依靠该索引找到全部令牌
+func (rs *RedisStore) AddFamilyToGrant(ctx context.Context, userID, clientID, familyID string, expire time.Duration) error {
+	grantKey := "oauth:grant:" + userID + ":" + clientID
+	if _, err := rs.redis.SaddCtx(ctx, grantKey, familyID); err != nil {
+		return err
+	}
+	if err := rs.redis.ExpireCtx(ctx, grantKey, int(expire.Seconds())); err != nil {
+		return err
+	}
+
+	usersKey := "oauth:client_users:" + clientID
+	if _, err := rs.redis.SaddCtx(ctx, usersKey, userID); err != nil {
+		return err
+	}
+	// 索引中各用户的令牌有效期不同，只延长不缩短
+	ttl, err := rs.redis.TtlCtx(ctx, usersKey)
+	if err != nil {
+		return err
+	}
+	if time.Duration(ttl)*time.Second >= expire {
+		return nil
+	}
+	return rs.redis.ExpireCtx(ctx, usersKey, int(expire.Seconds()))
+}
+
+// GrantUserIDs 返回持有客户端令牌的全部用户ID，客户端凭证模式的令牌记为空用户ID
+func (rs *RedisStore) GrantUserIDs(ctx context.Context, clientID string) ([]string, error) {
+	return rs.redis.SmembersCtx(ctx, "oauth:client_users:"+clientID)
+}
+
+// DeleteGrantUserIDs 删除客户端的用户索引
+func (rs *RedisStore) DeleteGrantUserIDs(ctx context.Context, clientID string) error {
+	_, err := rs.redis.DelCtx(ctx, "oauth:client_users:"+clientID)
+	return err
+}
+
+// RevokeGrant 吊销用户授权给客户端后签发的全部访问令牌和刷新令牌
+func (rs *RedisStore) RevokeGrant(ctx context.Context, userID, clientID string) error {
+	grantKey := "oauth:grant:" + userID + ":" + clientID
+	familyIDs, err := rs.redis.SmembersCtx(ctx, grantKey)
+	if err != nil {
+		return err
+	}
+
+	for _, familyID := range familyIDs {
+		if err := rs.RevokeFamily(ctx, familyID); err != nil {
+			return err
+		}
+	}
+
+	_, err = rs.redis.DelCtx(ctx, grantKey)
+	return err
+}
+
+func (rs *RedisStore) addToFamily(ctx context.Context, familyID, key string, expire time.Duration) error {
+	familyKey := "oauth:family:" + familyID
+	if _, err := rs.redis.SaddCtx(ctx, familyKey, key); err != nil {
+		return err
+	}
+
+	// 令牌族的有效期跟随最新加入的令牌
+	return rs.redis.ExpireCtx(ctx, familyKey, int(expire.Seconds()))
+}
+
+// StoreSigningKey 保存轮换生成的签名密钥，所有实例共用
+func (rs *RedisStore) StoreSigningKey(ctx context.Context, kid string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return rs.redis.HsetCtx(ctx, "oauth:signing_keys", kid, string(jsonData))
+}
+
+// SigningKeys 读取全部共用的签名密钥，键为kid
+func (rs *RedisStore) SigningKeys(ctx context.Context) (map[string]string, error) {
+	return rs.redis.HgetallCtx(ctx, "oauth:signing_keys")
+}
+
+// DeleteSigningKey 删除已停止验证的签名密钥
+func (rs *RedisStore) DeleteSigningKey(ctx context.Context, kid string) error {
+	_, err := rs.redis.HdelCtx(ctx, "oauth:signing_keys", kid)
+	return err
+}
+
+// LockKeyRotation 获取密钥轮换锁，多个实例同时到达轮换时间时只有一个实例生成新密钥
+func (rs *RedisStore) LockKeyRotation(ctx context.Context, expire time.Duration) (bool, error) {
+	return rs.redis.SetnxExCtx(ctx, "oauth:signing_keys:lock", "1", int(expire.Seconds()))
+}
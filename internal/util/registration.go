@@ -0,0 +1,301 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"oauth2-server/internal/model"
+)
+
+// 动态客户端注册错误码（RFC 7591 3.2.2）
+const (
+	RegistrationErrInvalidRedirectURI    = "invalid_redirect_uri"
+	RegistrationErrInvalidClientMetadata = "invalid_client_metadata"
+)
+
+// ErrInvalidRegistrationToken 注册访问令牌缺失、错误，或客户端不存在（RFC 7592 不区分这几种情况）
+var ErrInvalidRegistrationToken = errors.New("invalid_token")
+
+// tokenEndpointAuthMethods 客户端可以注册的令牌端点认证方式
+var tokenEndpointAuthMethods = []string{
+	model.AuthMethodClientSecretBasic,
+	model.AuthMethodClientSecretPost,
+	model.AuthMethodNone,
+}
+
+// RegistrationError 动态客户端注册的错误响应
+type RegistrationError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *RegistrationError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func invalidClientMetadata(description string) *RegistrationError {
+	return &RegistrationError{Code: RegistrationErrInvalidClientMetadata, Description: description}
+}
+
+// ClientMetadata RFC 7591 客户端元数据
+type ClientMetadata struct {
+	RedirectURIs            []string               `json:"redirect_uris"`
+	GrantTypes              []string               `json:"grant_types"`
+	ResponseTypes           []string               `json:"response_types"`
+	TokenEndpointAuthMethod string                 `json:"token_endpoint_auth_method"`
+	ClientName              string                 `json:"client_name,omitempty"`
+	LogoURI                 string                 `json:"logo_uri,omitempty"`
+	JWKS                    map[string]interface{} `json:"jwks,omitempty"`
+	Scope                   string                 `json:"scope,omitempty"`
+}
+
+// ClientRegistration RFC 7591/7592 客户端信息响应，密钥和注册访问令牌只在注册时返回
+type ClientRegistration struct {
+	ClientID                string `json:"client_id"`
+	ClientSecret            string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt        int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   int64  `json:"client_secret_expires_at"` // 0表示永不过期
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+	Status                  string `json:"status,omitempty"` // 等待审批时为pending
+	ClientMetadata
+}
+
+// Apply 校验元数据并写入客户端，未填写的字段按RFC 7591取默认值。
+// grantTypes为服务端支持的授权模式
+func (md *ClientMetadata) Apply(client *model.Client, scopes Scopes, grantTypes []string) error {
+	if len(md.GrantTypes) == 0 {
+		md.GrantTypes = []string{"authorization_code"}
+	}
+	allowed, err := NormalizeGrantTypes(strings.Join(md.GrantTypes, " "), grantTypes)
+	if err != nil {
+		return invalidClientMetadata("unsupported grant_types")
+	}
+	client.SetGrantTypes(allowed)
+
+	if len(md.ResponseTypes) == 0 {
+		md.ResponseTypes = client.ResponseTypes()
+	}
+	for _, rt := range md.ResponseTypes {
+		if !client.AllowsResponseType(rt) {
+			return invalidClientMetadata("response_types do not match grant_types")
+		}
+	}
+
+	// 使用授权端点的模式必须注册回调地址
+	if len(md.RedirectURIs) > 0 || len(client.ResponseTypes()) > 0 {
+		redirectURIs, err := NormalizeRedirectURIs("", md.RedirectURIs)
+		if err != nil {
+			return &RegistrationError{Code: RegistrationErrInvalidRedirectURI, Description: "invalid redirect_uris"}
+		}
+		client.SetRedirectURIs(redirectURIs)
+	} else {
+		client.SetRedirectURIs(nil)
+	}
+
+	if md.TokenEndpointAuthMethod == "" {
+		md.TokenEndpointAuthMethod = model.AuthMethodClientSecretBasic
+	}
+	if !contains(tokenEndpointAuthMethods, md.TokenEndpointAuthMethod) {
+		return invalidClientMetadata("unsupported token_endpoint_auth_method")
+	}
+	client.TokenEndpointAuthMethod = md.TokenEndpointAuthMethod
+
+	// 公开客户端无法保护密钥，不能使用客户端凭证模式，授权码模式必须使用PKCE
+	if client.IsPublic() {
+		if err := CheckPublicGrantTypes(client, client.GrantTypes()); err != nil {
+			return invalidClientMetadata(err.Error())
+		}
+		client.RequirePKCE = true
+	}
+
+	scope, err := scopes.Validate(md.Scope, md.Scope)
+	if err != nil {
+		return invalidClientMetadata("unknown scope")
+	}
+	client.Scope = scope
+
+	if md.LogoURI != "" {
+		if u, err := url.Parse(md.LogoURI); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalidClientMetadata("invalid logo_uri")
+		}
+	}
+	client.Name = md.ClientName
+	client.LogoURI = md.LogoURI
+
+	client.JWKS = ""
+	if md.JWKS != nil {
+		if _, ok := md.JWKS["keys"].([]interface{}); !ok {
+			return invalidClientMetadata("jwks must contain a keys array")
+		}
+		jwks, err := json.Marshal(md.JWKS)
+		if err != nil {
+			return invalidClientMetadata("invalid jwks")
+		}
+		client.JWKS = string(jwks)
+	}
+
+	return nil
+}
+
+// RegisterClient 按RFC 7591注册客户端，公开客户端不生成密钥
+func RegisterClient(ctx context.Context, clientModel model.ClientModel, scopeModel model.ScopeModel,
+	registrant *Registrant, md *ClientMetadata, grantTypes []string, issuer string) (*ClientRegistration, error) {
+	scopes, err := LoadScopes(ctx, scopeModel)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &model.Client{}
+	if err := md.Apply(client, scopes, grantTypes); err != nil {
+		return nil, err
+	}
+	if !registrant.AllowsScope(scopes, client.Scope) {
+		return nil, invalidClientMetadata("admin scope requires an admin token")
+	}
+	client.Status = registrant.ClientStatus()
+
+	var secret string
+	if !client.IsPublic() {
+		if secret, client.Secret, err = NewClientSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	token, hash, err := NewRegistrationToken()
+	if err != nil {
+		return nil, err
+	}
+	client.RegistrationToken = hash
+
+	if err := registrant.Consume(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := clientModel.Insert(ctx, client); err != nil {
+		return nil, err
+	}
+
+	reg := NewClientRegistration(client, issuer)
+	reg.ClientSecret = secret
+	reg.RegistrationAccessToken = token
+	return reg, nil
+}
+
+// FindRegisteredClient 使用注册访问令牌查找客户端，authorization为请求的Authorization头
+func FindRegisteredClient(ctx context.Context, clientModel model.ClientModel, clientID, authorization string) (*model.Client, error) {
+	token, ok := BearerToken(authorization)
+	if !ok {
+		return nil, ErrInvalidRegistrationToken
+	}
+
+	client, err := clientModel.FindOne(ctx, clientID)
+	if err == model.ErrNotFound {
+		return nil, ErrInvalidRegistrationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !VerifyRegistrationToken(client, token) {
+		return nil, ErrInvalidRegistrationToken
+	}
+	return client, nil
+}
+
+// UpdateRegisteredClient 按RFC 7592使用请求中的元数据整体替换客户端配置，密钥和注册访问令牌保持不变
+func UpdateRegisteredClient(ctx context.Context, clientModel model.ClientModel, scopeModel model.ScopeModel,
+	client *model.Client, md *ClientMetadata, grantTypes []string) error {
+	scopes, err := LoadScopes(ctx, scopeModel)
+	if err != nil {
+		return err
+	}
+
+	// 不允许通过更新在公开客户端和机密客户端之间切换
+	updated := *client
+	if err := md.Apply(&updated, scopes, grantTypes); err != nil {
+		return err
+	}
+	if updated.IsPublic() != client.IsPublic() {
+		return invalidClientMetadata("token_endpoint_auth_method cannot change between none and client secret methods")
+	}
+	if scopes.Contains(updated.Scope, AdminScope) && !scopes.Contains(client.Scope, AdminScope) {
+		return invalidClientMetadata("admin scope requires an admin token")
+	}
+
+	*client = updated
+	return clientModel.Update(ctx, client)
+}
+
+// NewClientRegistration 根据客户端生成RFC 7591/7592响应，issuer用于拼接管理地址
+func NewClientRegistration(client *model.Client, issuer string) *ClientRegistration {
+	md := ClientMetadata{
+		RedirectURIs:            client.RedirectURIs(),
+		GrantTypes:              client.GrantTypes(),
+		ResponseTypes:           client.ResponseTypes(),
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+		ClientName:              client.Name,
+		LogoURI:                 client.LogoURI,
+		Scope:                   client.Scope,
+	}
+	if client.JWKS != "" {
+		json.Unmarshal([]byte(client.JWKS), &md.JWKS)
+	}
+
+	status := ""
+	if !client.IsActive() {
+		status = client.Status
+	}
+
+	return &ClientRegistration{
+		ClientID:              client.ID,
+		Status:                status,
+		ClientIDIssuedAt:      client.CreatedAt.Unix(),
+		RegistrationClientURI: issuer + strings.Replace(DynamicClientPath, ":client_id", url.PathEscape(client.ID), 1),
+		ClientMetadata:        md,
+	}
+}
+
+// NewRegistrationToken 生成注册访问令牌，返回明文和用于存储的哈希
+func NewRegistrationToken() (token, hash string, err error) {
+	return NewClientSecret()
+}
+
+// VerifyRegistrationToken 校验客户端的注册访问令牌
+func VerifyRegistrationToken(client *model.Client, token string) bool {
+	return token != "" && IsHashedClientSecret(client.RegistrationToken) &&
+		VerifyClientSecret(client.RegistrationToken, token)
+}
+
+// BearerToken 从Authorization请求头中解析Bearer令牌
+func BearerToken(authorization string) (string, bool) {
+	parts := strings.SplitN(authorization, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// WriteRegistrationError 按RFC 7591/7592输出错误，初始访问令牌或注册访问令牌无效时返回401
+func WriteRegistrationError(w http.ResponseWriter, err error) {
+	var regErr *RegistrationError
+	switch {
+	case errors.As(err, &regErr):
+		writeJSON(w, http.StatusBadRequest, regErr)
+	case errors.Is(err, ErrInvalidRegistrationToken), errors.Is(err, ErrInvalidInitialAccessToken):
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeJSON(w, http.StatusUnauthorized, &RegistrationError{Code: ErrInvalidRegistrationToken.Error()})
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
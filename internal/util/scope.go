@@ -0,0 +1,115 @@
+package util
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	oauth2errors "github.com/go-oauth2/oauth2/v4/errors"
+
+	"oauth2-server/internal/model"
+)
+
+// ErrInvalidScope 请求的权限未注册或超出客户端允许的范围
+var ErrInvalidScope = oauth2errors.ErrInvalidScope
+
+// Scopes 已注册的权限范围
+type Scopes map[string]*model.Scope
+
+// LoadScopes 从数据库加载已注册的权限范围
+func LoadScopes(ctx context.Context, scopeModel model.ScopeModel) (Scopes, error) {
+	list, err := scopeModel.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make(Scopes, len(list))
+	for _, scope := range list {
+		scopes[scope.Name] = scope
+	}
+	return scopes, nil
+}
+
+// Names 返回全部已注册的权限名称，按名称排序，发布在元数据文档中
+func (s Scopes) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseScope 将以空格分隔的scope解析为去重后的集合，保持原有顺序
+func ParseScope(scope string) []string {
+	var names []string
+	for _, name := range strings.Fields(scope) {
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// WithoutScope 从以空格分隔的scope中移除指定权限
+func WithoutScope(scope, name string) string {
+	var names []string
+	for _, n := range ParseScope(scope) {
+		if n != name {
+			names = append(names, n)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// Expand 返回包含隐含权限在内的全部权限
+func (s Scopes) Expand(scope string) []string {
+	names := ParseScope(scope)
+	for i := 0; i < len(names); i++ {
+		registered, ok := s[names[i]]
+		if !ok {
+			continue
+		}
+		for _, implied := range registered.ImpliedScopes() {
+			if !contains(names, implied) {
+				names = append(names, implied)
+			}
+		}
+	}
+	return names
+}
+
+// Contains 判断scope及其隐含权限中是否包含指定权限
+func (s Scopes) Contains(scope, name string) bool {
+	return contains(s.Expand(scope), name)
+}
+
+// Validate 校验请求的权限都已注册且在allowed范围内，返回补全隐含权限后的scope，
+// 未请求任何权限时使用allowed
+func (s Scopes) Validate(requested, allowed string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		requested = allowed
+	}
+
+	allowedSet := s.Expand(allowed)
+	for _, name := range ParseScope(requested) {
+		if _, ok := s[name]; !ok || !contains(allowedSet, name) {
+			return "", ErrInvalidScope
+		}
+	}
+	return strings.Join(s.Expand(requested), " "), nil
+}
+
+// Describe 将scope转换为授权页面上的说明，未注册的权限直接展示名称
+func (s Scopes) Describe(scope string) []ScopeDescription {
+	var descriptions []ScopeDescription
+	for _, name := range ParseScope(scope) {
+		desc := ScopeDescription{Name: name, Description: name}
+		if registered, ok := s[name]; ok {
+			desc.Description = registered.Description
+			desc.Sensitive = registered.Sensitive
+		}
+		descriptions = append(descriptions, desc)
+	}
+	return descriptions
+}
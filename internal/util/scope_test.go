@@ -0,0 +1,99 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testScopes 与初始化脚本相同的内置权限，另加一个多级隐含的权限
+func testScopes() Scopes {
+	return Scopes{
+		"openid":  {Name: "openid"},
+		"userid":  {Name: "userid"},
+		"profile": {Name: "profile", Sensitive: true, Implies: "userid"},
+		"admin":   {Name: "admin", Sensitive: true},
+		"email":   {Name: "email", Implies: "profile"},
+	}
+}
+
+func TestScopesExpand(t *testing.T) {
+	scopes := testScopes()
+	tests := []struct {
+		scope string
+		want  []string
+	}{
+		{"", nil},
+		{"userid", []string{"userid"}},
+		{"profile", []string{"profile", "userid"}},
+		{"email", []string{"email", "profile", "userid"}},
+		{"userid profile", []string{"userid", "profile"}},
+		{"profile profile  openid", []string{"profile", "openid", "userid"}},
+		{"unknown", []string{"unknown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			if got := scopes.Expand(tt.scope); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand(%q) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopesValidate(t *testing.T) {
+	scopes := testScopes()
+	tests := []struct {
+		name      string
+		requested string
+		allowed   string
+		want      string
+		wantErr   bool
+	}{
+		{"请求注册的权限", "userid", "userid profile", "userid", false},
+		{"补全隐含权限", "profile", "profile", "profile userid", false},
+		{"通过隐含获得的权限也可以单独请求", "userid", "profile", "userid", false},
+		{"未请求时使用注册的全部权限", "", "userid profile", "userid profile", false},
+		{"空白等同于未请求", "  ", "profile", "profile userid", false},
+		{"去除重复", "userid userid", "userid", "userid", false},
+		{"超出注册范围", "admin", "userid profile", "", true},
+		{"隐含权限不能反推", "profile", "userid", "", true},
+		{"未注册的权限", "unknown", "unknown", "", true},
+		{"部分超出范围", "userid admin", "userid", "", true},
+		{"注册范围为空", "userid", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scopes.Validate(tt.requested, tt.allowed)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Validate(%q, %q) = %q, %v, want %q, wantErr %v", tt.requested, tt.allowed, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithoutScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		name  string
+		want  string
+	}{
+		{"openid profile", "openid", "profile"},
+		{"profile", "openid", "profile"},
+		{"openid", "openid", ""},
+		{"userid openid openid profile", "openid", "userid profile"},
+	}
+	for _, tt := range tests {
+		if got := WithoutScope(tt.scope, tt.name); got != tt.want {
+			t.Errorf("WithoutScope(%q, %q) = %q, want %q", tt.scope, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScopesNames(t *testing.T) {
+	want := []string{"admin", "email", "openid", "profile", "userid"}
+	if got := testScopes().Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if got := (Scopes{}).Names(); len(got) != 0 {
+		t.Errorf("Names() of empty scopes = %v", got)
+	}
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/google/uuid"
+
+	"oauth2-server/internal/model"
+)
+
+// clientSecretPrefix 加盐哈希后的客户端密钥前缀，格式为 sha256$<盐>$<哈希>
+const clientSecretPrefix = "sha256$"
+
+// NewClientSecret 生成新的客户端密钥，返回明文和用于存储的哈希，明文只应返回给调用方一次
+func NewClientSecret() (secret, hash string, err error) {
+	secret = uuid.New().String()
+	hash, err = HashClientSecret(secret)
+	return
+}
+
+// HashClientSecret 使用随机盐对客户端密钥进行SHA-256哈希
+func HashClientSecret(secret string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return clientSecretPrefix + hex.EncodeToString(salt) + "$" + hashClientSecret(salt, secret), nil
+}
+
+// VerifyClientSecret 使用常量时间比较校验客户端密钥，兼容尚未迁移的明文密钥。
+// 未设置密钥时任何输入都校验失败，公开客户端应在调用前单独处理
+func VerifyClientSecret(stored, secret string) bool {
+	if stored == "" {
+		return false
+	}
+	if !IsHashedClientSecret(stored) {
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(secret)) == 1
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(stored, clientSecretPrefix), "$", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(parts[1]), []byte(hashClientSecret(salt, secret))) == 1
+}
+
+// IsHashedClientSecret 判断存储的客户端密钥是否已经哈希
+func IsHashedClientSecret(stored string) bool {
+	return strings.HasPrefix(stored, clientSecretPrefix)
+}
+
+// MigrateClientSecrets 将数据库中的明文客户端密钥迁移为加盐哈希，返回迁移的数量，公开客户端没有密钥无需迁移
+func MigrateClientSecrets(ctx context.Context, clientModel model.ClientModel) (int, error) {
+	clients, err := clientModel.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, client := range clients {
+		if client.Secret == "" || IsHashedClientSecret(client.Secret) {
+			continue
+		}
+
+		client.Secret, err = HashClientSecret(client.Secret)
+		if err != nil {
+			return migrated, err
+		}
+		if err = clientModel.Update(ctx, client); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// ClientsWithoutSecret 返回没有密钥的机密客户端ID，这些客户端在生成密钥前无法认证
+func ClientsWithoutSecret(ctx context.Context, clientModel model.ClientModel) ([]string, error) {
+	clients, err := clientModel.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, client := range clients {
+		if needsSecret(client) {
+			ids = append(ids, client.ID)
+		}
+	}
+	return ids, nil
+}
+
+// BootstrapClientSecrets 为没有密钥的机密客户端（如初始化脚本创建的admin_client）生成密钥，
+// 数据库中只保存哈希，返回客户端ID到明文密钥的映射，明文只应输出一次
+func BootstrapClientSecrets(ctx context.Context, clientModel model.ClientModel) (map[string]string, error) {
+	clients, err := clientModel.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]string)
+	for _, client := range clients {
+		if !needsSecret(client) {
+			continue
+		}
+
+		secret, hash, err := NewClientSecret()
+		if err != nil {
+			return secrets, err
+		}
+		client.Secret = hash
+		if err = clientModel.Update(ctx, client); err != nil {
+			return secrets, err
+		}
+		secrets[client.ID] = secret
+	}
+	return secrets, nil
+}
+
+func needsSecret(client *model.Client) bool {
+	return client.Secret == "" && !client.IsPublic()
+}
+
+func hashClientSecret(salt []byte, secret string) string {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), secret...))
+	return hex.EncodeToString(sum[:])
+}
+
+// OAuthClient 适配go-oauth2的客户端，Secret保存的是密钥哈希，校验时按哈希比较
+type OAuthClient struct {
+	models.Client
+}
+
+// NewOAuthClient 根据数据库中的客户端创建go-oauth2客户端
+func NewOAuthClient(client *model.Client) *OAuthClient {
+	return &OAuthClient{
+		Client: models.Client{
+			ID:     client.ID,
+			Secret: client.Secret,
+			Domain: client.RedirectURL,
+			Public: client.IsPublic(),
+		},
+	}
+}
+
+// VerifyPassword 实现oauth2.ClientPasswordVerifier，公开客户端不校验密钥
+func (c *OAuthClient) VerifyPassword(secret string) bool {
+	if c.Public {
+		return true
+	}
+	return VerifyClientSecret(c.Secret, secret)
+}
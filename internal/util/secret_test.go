@@ -0,0 +1,52 @@
+package util
+
+import "testing"
+
+func TestVerifyClientSecret(t *testing.T) {
+	hash, err := HashClientSecret("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		stored string
+		secret string
+		want   bool
+	}{
+		{"哈希匹配", hash, "s3cret", true},
+		{"哈希不匹配", hash, "other", false},
+		{"哈希时提交空密钥", hash, "", false},
+		{"明文匹配", "plain", "plain", true},
+		{"明文不匹配", "plain", "plain2", false},
+		{"未设置密钥时提交空密钥", "", "", false},
+		{"未设置密钥时提交任意密钥", "", "anything", false},
+		{"哈希格式缺少分隔符", "sha256$abc", "abc", false},
+		{"盐不是十六进制", "sha256$zz$00", "", false},
+		{"提交哈希值本身", hash, hash, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyClientSecret(tt.stored, tt.secret); got != tt.want {
+				t.Errorf("VerifyClientSecret(%q, %q) = %v, want %v", tt.stored, tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashClientSecretUsesRandomSalt(t *testing.T) {
+	a, err := HashClientSecret("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := HashClientSecret("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("two hashes of the same secret are equal: %q", a)
+	}
+	if !IsHashedClientSecret(a) {
+		t.Errorf("IsHashedClientSecret(%q) = false", a)
+	}
+}
@@ -0,0 +1,182 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey JWT签名密钥
+type SigningKey struct {
+	ID          string            // 密钥ID，写入JWT头部的kid
+	Method      jwt.SigningMethod // 签名算法
+	SignedKey   []byte            // 密钥原文：HS*为共享密钥，其余为私钥PEM，与generates.JWTAccessGenerate保持一致
+	NotBefore   time.Time         // 开始用于签名的时间，零值表示立即生效
+	RetireAfter time.Time         // 停止用于验证的时间，零值表示由密钥集合根据接替密钥自动计算
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACSigningKey 创建基于共享密钥的HS256签名密钥
+func NewHMACSigningKey(secret string) *SigningKey {
+	return &SigningKey{
+		Method:    jwt.SigningMethodHS256,
+		SignedKey: []byte(secret),
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// LoadSigningKey 从PEM文件加载非对称签名密钥，支持RS*/PS*/ES*/EdDSA
+// keyID为空时使用公钥指纹作为kid
+func LoadSigningKey(method, privateKeyFile, keyID string) (*SigningKey, error) {
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSigningKey(method, data, keyID)
+}
+
+// ParseSigningKey 从PEM数据解析非对称签名密钥，keyID为空时使用公钥指纹作为kid
+func ParseSigningKey(method string, data []byte, keyID string) (*SigningKey, error) {
+	signingMethod := jwt.GetSigningMethod(method)
+	if signingMethod == nil {
+		return nil, fmt.Errorf("unsupported signing method: %s", method)
+	}
+
+	key := &SigningKey{
+		ID:        keyID,
+		Method:    signingMethod,
+		SignedKey: data,
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(method, "RS"), strings.HasPrefix(method, "PS"):
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey, key.verifyKey = privateKey, &privateKey.PublicKey
+	case strings.HasPrefix(method, "ES"):
+		privateKey, err := jwt.ParseECPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey, key.verifyKey = privateKey, &privateKey.PublicKey
+	case method == jwt.SigningMethodEdDSA.Alg():
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey, key.verifyKey = privateKey, privateKey.(crypto.Signer).Public()
+	default:
+		return nil, fmt.Errorf("signing method %s requires a shared secret", method)
+	}
+
+	if key.ID == "" {
+		if key.ID, err = publicKeyThumbprint(key.verifyKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// GenerateSigningKey 生成新的非对称签名密钥，用于自动轮换
+func GenerateSigningKey(method string) (*SigningKey, error) {
+	signingMethod := jwt.GetSigningMethod(method)
+	if signingMethod == nil {
+		return nil, fmt.Errorf("unsupported signing method: %s", method)
+	}
+
+	var privateKey crypto.Signer
+	var err error
+	switch method {
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ES512":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "EdDSA":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("signing method %s requires a shared secret", method)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 保留PEM原文，供go-oauth2的JWTAccessGenerate使用
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{
+		Method:    signingMethod,
+		SignedKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+	}
+	if key.ID, err = publicKeyThumbprint(key.verifyKey); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// IsSymmetric 是否为共享密钥签名，共享密钥不能对外发布
+func (k *SigningKey) IsSymmetric() bool {
+	_, ok := k.verifyKey.([]byte)
+	return ok
+}
+
+// PublicKey 返回用于验证签名的公钥
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	if k.IsSymmetric() {
+		return nil
+	}
+	return k.verifyKey
+}
+
+func (k *SigningKey) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method, claims)
+	if k.ID != "" {
+		token.Header["kid"] = k.ID
+	}
+	return token.SignedString(k.signKey)
+}
+
+// publicKeyThumbprint 使用公钥DER编码的SHA-256摘要作为默认kid
+func publicKeyThumbprint(publicKey interface{}) (string, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return "", errors.New("unsupported public key type")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// RenderTemplate 使用模板文件渲染页面，页面包含用户数据，禁止缓存
+func RenderTemplate(w http.ResponseWriter, filename string, data interface{}) error {
+	tmpl, err := template.ParseFiles(filename)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	return tmpl.Execute(w, data)
+}
+
+// ErrorPage 授权请求无法重定向回客户端时展示的错误页面数据
+type ErrorPage struct {
+	Error       string // 错误码
+	Description string // 错误描述
+}
+
+// RenderErrorPage 以400状态码展示错误页面，用于客户端或回调地址无效、无法安全重定向的授权请求。
+// 不携带WWW-Authenticate，避免浏览器弹出登录对话框
+func RenderErrorPage(w http.ResponseWriter, code, description string) {
+	tmpl, err := template.ParseFiles("static/error.html")
+	if err != nil {
+		http.Error(w, code+": "+description, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusBadRequest)
+	tmpl.Execute(w, &ErrorPage{Error: code, Description: description})
+}
@@ -0,0 +1,290 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/google/uuid"
+)
+
+// TokenStore 基于Redis的go-oauth2令牌存储，与go-zero服务共用 oauth:code:、oauth:token:、oauth:refresh: 的键和数据格式，
+// 并同样维护令牌族和用户授权索引，撤销授权或删除客户端时可以一并吊销
+type TokenStore struct {
+	rs            *RedisStore
+	refreshExpire time.Duration
+}
+
+// NewTokenStore 创建令牌存储，refreshExpire为未设置有效期的刷新令牌在Redis中的保存时长
+func NewTokenStore(rs *RedisStore, refreshExpire time.Duration) *TokenStore {
+	return &TokenStore{rs: rs, refreshExpire: refreshExpire}
+}
+
+// tokenData Redis中保存的令牌数据，前半部分字段与go-zero服务写入的一致，时间均为Unix秒
+type tokenData struct {
+	ClientID            string `json:"client_id"`
+	UserID              string `json:"user_id"`
+	Scope               string `json:"scope"`
+	RedirectURI         string `json:"redirect_uri,omitempty"`
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+	FamilyID            string `json:"family_id,omitempty"`
+	AuthTime            int64  `json:"auth_time,omitempty"`
+
+	// 以下字段只由go-oauth2写入，go-zero服务写入的数据按键的剩余有效期补全
+	Code             string `json:"code,omitempty"`
+	CodeCreateAt     int64  `json:"code_create_at,omitempty"`
+	CodeExpiresIn    int64  `json:"code_expires_in,omitempty"`
+	Access           string `json:"access,omitempty"`
+	AccessCreateAt   int64  `json:"access_create_at,omitempty"`
+	AccessExpiresIn  int64  `json:"access_expires_in,omitempty"`
+	Refresh          string `json:"refresh,omitempty"`
+	RefreshCreateAt  int64  `json:"refresh_create_at,omitempty"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in,omitempty"`
+}
+
+// familyToken 从Redis读取的令牌，刷新时go-oauth2会复用该对象，据此沿用原令牌族
+type familyToken struct {
+	*models.Token
+	familyID string
+	authTime int64
+}
+
+func newTokenData(info oauth2.TokenInfo) *tokenData {
+	return &tokenData{
+		ClientID:            info.GetClientID(),
+		UserID:              info.GetUserID(),
+		Scope:               info.GetScope(),
+		RedirectURI:         info.GetRedirectURI(),
+		CodeChallenge:       info.GetCodeChallenge(),
+		CodeChallengeMethod: info.GetCodeChallengeMethod().String(),
+		Code:                info.GetCode(),
+		CodeCreateAt:        unixOrZero(info.GetCodeCreateAt()),
+		CodeExpiresIn:       int64(info.GetCodeExpiresIn().Seconds()),
+		Access:              info.GetAccess(),
+		AccessCreateAt:      unixOrZero(info.GetAccessCreateAt()),
+		AccessExpiresIn:     int64(info.GetAccessExpiresIn().Seconds()),
+		Refresh:             info.GetRefresh(),
+		RefreshCreateAt:     unixOrZero(info.GetRefreshCreateAt()),
+		RefreshExpiresIn:    int64(info.GetRefreshExpiresIn().Seconds()),
+	}
+}
+
+func (d *tokenData) token() *familyToken {
+	t := models.NewToken()
+	t.ClientID = d.ClientID
+	t.UserID = d.UserID
+	t.Scope = d.Scope
+	t.RedirectURI = d.RedirectURI
+	t.CodeChallenge = d.CodeChallenge
+	t.CodeChallengeMethod = d.CodeChallengeMethod
+	t.Code = d.Code
+	t.CodeCreateAt = time.Unix(d.CodeCreateAt, 0)
+	t.CodeExpiresIn = time.Duration(d.CodeExpiresIn) * time.Second
+	t.Access = d.Access
+	t.AccessCreateAt = time.Unix(d.AccessCreateAt, 0)
+	t.AccessExpiresIn = time.Duration(d.AccessExpiresIn) * time.Second
+	t.Refresh = d.Refresh
+	t.RefreshCreateAt = time.Unix(d.RefreshCreateAt, 0)
+	t.RefreshExpiresIn = time.Duration(d.RefreshExpiresIn) * time.Second
+	// 兑换授权码时go-oauth2创建新的令牌，只沿用扩展字段，登录时间通过扩展字段带到访问令牌
+	if d.AuthTime != 0 {
+		t.Extension.Set(extensionAuthTime, strconv.FormatInt(d.AuthTime, 10))
+	}
+	return &familyToken{Token: t, familyID: d.FamilyID, authTime: d.AuthTime}
+}
+
+// extensionAuthTime 令牌扩展字段中保存的用户登录时间
+const extensionAuthTime = "auth_time"
+
+// authTimeKey 上下文中保存的用户登录时间
+type authTimeKey struct{}
+
+// ContextWithAuthTime 在上下文中保存用户登录的时间（Unix秒），签发授权码或隐式授权令牌时记为auth_time
+func ContextWithAuthTime(ctx context.Context, authTime int64) context.Context {
+	return context.WithValue(ctx, authTimeKey{}, authTime)
+}
+
+// Create 保存授权码，或保存访问令牌和刷新令牌。新签发的令牌开启新的令牌族，刷新得到的令牌沿用原令牌族
+func (s *TokenStore) Create(ctx context.Context, info oauth2.TokenInfo) error {
+	data := newTokenData(info)
+	if ft, ok := info.(*familyToken); ok {
+		data.FamilyID = ft.familyID
+		data.AuthTime = ft.authTime
+	}
+	if eti, ok := info.(oauth2.ExtendableTokenInfo); ok && data.AuthTime == 0 {
+		data.AuthTime, _ = strconv.ParseInt(eti.GetExtension().Get(extensionAuthTime), 10, 64)
+	}
+	if data.AuthTime == 0 {
+		data.AuthTime, _ = ctx.Value(authTimeKey{}).(int64)
+	}
+
+	if data.Code != "" {
+		if data.AuthTime == 0 {
+			data.AuthTime = data.CodeCreateAt
+		}
+		return s.rs.StoreCode(ctx, data.Code, data, info.GetCodeExpiresIn())
+	}
+
+	if data.FamilyID == "" {
+		data.FamilyID = uuid.New().String()
+	}
+	if data.AuthTime == 0 {
+		data.AuthTime = data.AccessCreateAt
+	}
+
+	// 令牌族和授权索引的有效期跟随最晚过期的令牌
+	expire := info.GetAccessExpiresIn()
+	if err := s.rs.StoreAccessToken(ctx, data.Access, data, expire); err != nil {
+		return err
+	}
+	if data.Refresh != "" {
+		refreshExpire := info.GetRefreshExpiresIn()
+		if refreshExpire <= 0 {
+			refreshExpire = s.refreshExpire
+		}
+		if err := s.rs.StoreRefreshToken(ctx, data.Refresh, data, refreshExpire); err != nil {
+			return err
+		}
+		if refreshExpire > expire {
+			expire = refreshExpire
+		}
+		if err := s.rs.AddRefreshTokenToFamily(ctx, data.FamilyID, data.Refresh, expire); err != nil {
+			return err
+		}
+	}
+	if err := s.rs.AddAccessTokenToFamily(ctx, data.FamilyID, data.Access, expire); err != nil {
+		return err
+	}
+
+	// 客户端凭证模式的令牌不关联用户，userID为空，删除客户端时一并吊销
+	return s.rs.AddFamilyToGrant(ctx, data.UserID, data.ClientID, data.FamilyID, expire)
+}
+
+// RemoveByCode 删除授权码
+func (s *TokenStore) RemoveByCode(ctx context.Context, code string) error {
+	return s.rs.DeleteCode(ctx, code)
+}
+
+// RemoveByAccess 删除访问令牌，go-zero服务签发的刷新令牌不记录访问令牌，此时access为空
+func (s *TokenStore) RemoveByAccess(ctx context.Context, access string) error {
+	if access == "" {
+		return nil
+	}
+	return s.rs.DeleteAccessToken(ctx, access)
+}
+
+// RemoveByRefresh 删除刷新令牌，并与go-zero服务一样标记为已轮换，再次使用时按重放处理
+func (s *TokenStore) RemoveByRefresh(ctx context.Context, refresh string) error {
+	if refresh == "" {
+		return nil
+	}
+
+	t, err := s.load(ctx, refresh, s.rs.GetRefreshToken)
+	if err != nil {
+		return err
+	}
+	if t != nil && t.familyID != "" {
+		if _, err := s.rs.MarkRefreshTokenUsed(ctx, refresh, t.familyID, s.refreshExpire); err != nil {
+			return err
+		}
+	}
+	return s.rs.DeleteRefreshToken(ctx, refresh)
+}
+
+// GetByCode 读取授权码，不存在时返回nil
+func (s *TokenStore) GetByCode(ctx context.Context, code string) (oauth2.TokenInfo, error) {
+	t, err := s.load(ctx, code, s.rs.GetCode)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	if t.Code == "" {
+		t.Code = code
+		t.CodeCreateAt, t.CodeExpiresIn, err = s.remaining(ctx, "oauth:code:"+code)
+	}
+	return t, err
+}
+
+// GetByAccess 读取访问令牌，不存在或已吊销时返回nil
+func (s *TokenStore) GetByAccess(ctx context.Context, access string) (oauth2.TokenInfo, error) {
+	t, err := s.load(ctx, access, s.rs.GetAccessToken)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	if t.Access == "" {
+		t.Access = access
+		t.AccessCreateAt, t.AccessExpiresIn, err = s.remaining(ctx, "oauth:token:"+access)
+	}
+	return t, err
+}
+
+// GetByRefresh 读取刷新令牌，不存在或已吊销时返回nil。
+// 已被任一服务轮换过的刷新令牌再次出现说明可能被盗用，吊销其所属的整个令牌族并返回nil
+func (s *TokenStore) GetByRefresh(ctx context.Context, refresh string) (oauth2.TokenInfo, error) {
+	if refresh == "" {
+		return nil, nil
+	}
+	familyID, err := s.rs.UsedRefreshTokenFamily(ctx, refresh)
+	if err != nil {
+		return nil, err
+	}
+	if familyID != "" {
+		return nil, s.rs.RevokeFamily(ctx, familyID)
+	}
+
+	t, err := s.load(ctx, refresh, s.rs.GetRefreshToken)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	if t.Refresh == "" {
+		t.Refresh = refresh
+		t.RefreshCreateAt, t.RefreshExpiresIn, err = s.remaining(ctx, "oauth:refresh:"+refresh)
+	}
+	return t, err
+}
+
+func (s *TokenStore) load(ctx context.Context, value string, get func(context.Context, string) (string, error)) (*familyToken, error) {
+	if value == "" {
+		return nil, nil
+	}
+	raw, err := get(ctx, value)
+	if err != nil || raw == "" {
+		return nil, err
+	}
+
+	var data tokenData
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, err
+	}
+	return data.token(), nil
+}
+
+// remaining 以当前时间和键的剩余有效期作为go-zero服务写入的令牌的签发时间和有效期，没有过期时间的键有效期为0
+func (s *TokenStore) remaining(ctx context.Context, key string) (time.Time, time.Duration, error) {
+	ttl, err := s.rs.redis.TtlCtx(ctx, key)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	return time.Now(), time.Duration(ttl) * time.Second, nil
+}
+
+// TokenFamily 返回从TokenStore读取的令牌所属的令牌族，其他来源的令牌返回空
+func TokenFamily(info oauth2.TokenInfo) string {
+	if ft, ok := info.(*familyToken); ok {
+		return ft.familyID
+	}
+	return ""
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -11,31 +13,41 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/go-oauth2/oauth2/v4"
 	"github.com/go-oauth2/oauth2/v4/errors"
 	"github.com/go-oauth2/oauth2/v4/generates"
 	"github.com/go-oauth2/oauth2/v4/manage"
-	"github.com/go-oauth2/oauth2/v4/models"
 	"github.com/go-oauth2/oauth2/v4/server"
-	"github.com/go-oauth2/oauth2/v4/store"
 	"github.com/go-session/session/v3"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"github.com/zeromicro/go-zero/rest/pathvar"
 
 	"oauth2-server/internal/config"
+	"oauth2-server/internal/middleware"
 	"oauth2-server/internal/model"
+	"oauth2-server/internal/oautherr"
+	"oauth2-server/internal/types"
+	"oauth2-server/internal/util"
 )
 
 var (
-	dumpvar bool
-	portvar int
+	dumpvar      bool
+	portvar      int
+	bootstrapvar bool
 )
 
 func init() {
-	flag.BoolVar(&dumpvar, "d", true, "Dump requests and responses")
+	flag.BoolVar(&dumpvar, "d", false, "Dump requests with credentials redacted")
 	flag.IntVar(&portvar, "p", 9096, "the base port for the server")
+	flag.BoolVar(&bootstrapvar, "bootstrap-secrets", false, "Generate secrets for confidential clients without one, print them to stderr and exit")
 }
 
 func main() {
@@ -53,64 +65,119 @@ func main() {
 	manager := manage.NewDefaultManager()
 	manager.SetAuthorizeCodeTokenCfg(manage.DefaultAuthorizeCodeTokenCfg)
 
-	// 使用Redis存储token
-	// redisStore := redis.MustNewRedis(c.Redis)
-	// 暂时使用内存存储，后续可以改为Redis存储
-	manager.MustTokenStorage(store.NewMemoryTokenStore())
+	// 使用Redis存储token，与go-zero服务共用键和数据格式，重启或多实例部署时令牌仍然有效
+	redisStore := util.NewRedisStore(*redis.MustNewRedis(c.Redis))
+	manager.MapTokenStorage(util.NewTokenStore(redisStore, time.Duration(c.Auth.RefreshExpire)*time.Second))
 
-	// 生成JWT访问令牌
-	manager.MapAccessGenerate(generates.NewJWTAccessGenerate("", []byte(c.Auth.AccessSecret), jwt.SigningMethodHS512))
+	// 生成JWT访问令牌，配置了私钥文件时使用非对称签名，并按密钥集合轮换，轮换生成的密钥与go-zero服务共用
+	keySet := util.MustNewKeySet(c.Auth, redisStore)
+	manager.MapAccessGenerate(&keySetAccessGenerate{keys: keySet})
 	// manager.MapAccessGenerate(generates.NewAccessGenerate())
 
 	// 创建数据库连接
 	conn := sqlx.NewMysql(c.MySQL.DataSource)
-	clientModel := model.NewClientModel(conn)
+	// 客户端存储直接读取MySQL并缓存在进程内，新注册或修改的客户端无需重启即可生效
+	clientModel := util.MustNewClientStore(model.NewClientModel(conn), c.ClientCache)
+	userModel := model.NewUserModel(conn)
+	scopeModel := model.NewScopeModel(conn)
+	initialTokenModel := model.NewInitialAccessTokenModel(conn)
+	authorizationModel := model.NewAuthorizationModel(conn)
 
-	// 创建客户端存储
-	clientStore := store.NewClientStore()
-
-	// 从数据库加载客户端
-	clients, err := clientModel.FindAll(context.Background())
+	// 将明文存储的客户端密钥迁移为加盐哈希，数据库不可用时直接退出
+	migrated, err := util.MigrateClientSecrets(context.Background(), clientModel)
+	if err != nil {
+		log.Fatalln("migrate client secrets failed:", err)
+	}
+	if migrated > 0 {
+		log.Println("migrated client secrets:", migrated)
+	}
 
-	if err == nil {
-		for _, client := range clients {
-			log.Println(client.ID, " ", client.Secret, " ", client.RedirectURL)
-			clientStore.Set(client.ID, &models.Client{
-				ID:     client.ID,
-				Secret: client.Secret,
-				Domain: client.RedirectURL,
-			})
+	// 没有密钥的机密客户端无法认证，由运维人员使用-bootstrap-secrets生成密钥，
+	// 明文只输出到标准错误一次，不写入日志
+	if bootstrapvar {
+		secrets, err := util.BootstrapClientSecrets(context.Background(), clientModel)
+		if err != nil {
+			log.Fatalln("bootstrap client secrets failed:", err)
+		}
+		for clientID, secret := range secrets {
+			fmt.Fprintf(os.Stderr, "%s\t%s\n", clientID, secret)
 		}
+		return
+	}
+	pending, err := util.ClientsWithoutSecret(context.Background(), clientModel)
+	if err != nil {
+		log.Fatalln("load clients failed:", err)
+	}
+	if len(pending) > 0 {
+		log.Println("clients without a secret cannot authenticate, run with -bootstrap-secrets to generate:", strings.Join(pending, ", "))
 	}
 
-	manager.MapClientStorage(clientStore)
+	manager.MapClientStorage(clientModel)
+
+	// 回调地址必须与注册的某个地址完全一致，回环地址允许任意端口
+	manager.SetValidateURIHandler(util.ValidateRedirectURIHandler)
 
 	// 创建OAuth2服务器
 	srv := server.NewServer(server.NewConfig(), manager)
 
 	// 设置密码授权处理器
 	srv.SetPasswordAuthorizationHandler(func(ctx context.Context, clientID, username, password string) (userID string, err error) {
-		// 这里应该实现真实的用户认证逻辑
-		if username == "test" && password == "test" {
-			userID = "test_user"
-		} else {
-			err = errors.New("invalid username or password")
+		user, err := util.AuthenticateUser(ctx, userModel, username, password)
+		if err == util.ErrInvalidCredentials {
+			// 返回空的用户ID，go-oauth2按invalid_grant响应
+			return "", nil
 		}
-		return
+		if err != nil {
+			return "", err
+		}
+		return user.ID, nil
+	})
+
+	// 客户端只能使用注册时允许的授权模式
+	srv.SetClientAuthorizedHandler(func(clientID string, grant oauth2.GrantType) (allowed bool, err error) {
+		client, err := clientModel.FindByID(context.Background(), clientID)
+		if err != nil || !client.IsActive() {
+			return false, errors.ErrInvalidClient
+		}
+		return client.AllowsGrantType(grant.String()), nil
+	})
+
+	// 客户端凭证和密码模式只能申请客户端注册的权限
+	srv.SetClientScopeHandler(func(tgr *oauth2.TokenGenerateRequest) (allowed bool, err error) {
+		client, err := clientModel.FindByID(tgr.Request.Context(), tgr.ClientID)
+		if err != nil {
+			return false, errors.ErrInvalidClient
+		}
+		return checkScope(tgr.Request.Context(), scopeModel, tgr.Scope, client.Scope)
 	})
 
+	// 刷新令牌时权限范围只能缩小
+	srv.SetRefreshingScopeHandler(func(tgr *oauth2.TokenGenerateRequest, oldScope string) (allowed bool, err error) {
+		return checkScope(tgr.Request.Context(), scopeModel, tgr.Scope, oldScope)
+	})
+
+	// 管理接口使用本服务签发的、包含admin权限的访问令牌认证
+	adminVerifier := func(ctx context.Context, token string) bool {
+		ti, err := srv.Manager.LoadAccessToken(ctx, token)
+		return err == nil && util.HasAdminScope(ti.GetScope())
+	}
+
 	// 设置用户授权处理器
-	srv.SetUserAuthorizationHandler(userAuthorizeHandler)
+	srv.SetUserAuthorizationHandler(userAuthorizeHandler(authorizationModel, c.ConsentExpire))
 
-	// 设置内部错误处理器
+	// 设置内部错误处理器，能识别的错误转换为对应的OAuth2错误码，其余按server_error响应
 	srv.SetInternalErrorHandler(func(err error) (re *errors.Response) {
+		if e := oautherr.From(err); e.Code != oautherr.ServerError {
+			return e.Response()
+		}
 		log.Println("Internal Error:", err.Error())
 		return
 	})
 
-	// 设置响应错误处理器
+	// 设置响应错误处理器，按RFC 6749修正状态码
 	srv.SetResponseErrorHandler(func(re *errors.Response) {
 		log.Println("Response Error:", re.Error.Error())
+		oautherr.Normalize(re)
 	})
 
 	// 创建HTTP服务器
@@ -118,266 +185,1502 @@ func main() {
 	defer server.Stop()
 
 	// 注册路由
-	registerRoutes(server, srv, clientModel, c)
+	registerRoutes(server, srv, clientModel, userModel, authorizationModel, scopeModel, initialTokenModel, redisStore,
+		keySet, adminVerifier, c)
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
 }
 
-func registerRoutes(server *rest.Server, srv *server.Server, clientModel model.ClientModel, c config.Config) {
-	// 客户端注册接口
+func registerRoutes(server *rest.Server, srv *server.Server, clientModel model.ClientModel, userModel model.UserModel,
+	authorizationModel model.AuthorizationModel, scopeModel model.ScopeModel, initialTokenModel model.InitialAccessTokenModel,
+	redisStore *util.RedisStore, keySet *util.KeySet, adminVerifier util.AdminVerifier, c config.Config) {
+	// 客户端和初始访问令牌管理接口需要admin权限的访问令牌
+	adminAuth := middleware.NewAdminAuthMiddleware(adminVerifier)
+	registrar := &clientRegistrar{
+		initialTokenModel: initialTokenModel,
+		adminVerifier:     adminVerifier,
+		requireApproval:   c.Registration.RequireApproval,
+	}
+
+	// 客户端注册接口，需要admin访问令牌或初始访问令牌
+	server.AddRoute(rest.Route{
+		Method:  http.MethodPost,
+		Path:    util.ClientRegisterPath,
+		Handler: clientRegisterHandler(clientModel, scopeModel, registrar, supportedGrantTypes(srv.Config)),
+	})
+
+	// 客户端、初始访问令牌和签名密钥管理接口统一挂在admin认证中间件下
+	server.AddRoutes(rest.WithMiddlewares([]rest.Middleware{adminAuth.Handle},
+		// 客户端列表
+		rest.Route{
+			Method:  http.MethodGet,
+			Path:    util.ClientsPath,
+			Handler: listClientsHandler(clientModel),
+		},
+		// 客户端详情
+		rest.Route{
+			Method:  http.MethodGet,
+			Path:    util.ClientPath,
+			Handler: getClientHandler(clientModel),
+		},
+		// 更新客户端
+		rest.Route{
+			Method:  http.MethodPut,
+			Path:    util.ClientPath,
+			Handler: updateClientHandler(clientModel, scopeModel, supportedGrantTypes(srv.Config)),
+		},
+		// 删除客户端
+		rest.Route{
+			Method:  http.MethodDelete,
+			Path:    util.ClientPath,
+			Handler: deleteClientHandler(clientModel, authorizationModel, redisStore),
+		},
+		// 重新生成客户端密钥
+		rest.Route{
+			Method:  http.MethodPost,
+			Path:    util.ClientSecretPath,
+			Handler: resetClientSecretHandler(clientModel),
+		},
+		// 审批通过等待中的客户端
+		rest.Route{
+			Method:  http.MethodPost,
+			Path:    util.ClientApprovePath,
+			Handler: approveClientHandler(clientModel),
+		},
+		// 初始访问令牌管理
+		rest.Route{
+			Method:  http.MethodPost,
+			Path:    util.InitialTokensPath,
+			Handler: createInitialTokenHandler(initialTokenModel),
+		},
+		rest.Route{
+			Method:  http.MethodGet,
+			Path:    util.InitialTokensPath,
+			Handler: listInitialTokensHandler(initialTokenModel),
+		},
+		rest.Route{
+			Method:  http.MethodDelete,
+			Path:    util.InitialTokenPath,
+			Handler: deleteInitialTokenHandler(initialTokenModel),
+		},
+		// 按需轮换签名密钥
+		rest.Route{
+			Method:  http.MethodPost,
+			Path:    util.SigningKeyRotatePath,
+			Handler: rotateSigningKeyHandler(keySet, time.Duration(c.Auth.RotatePrepublish)*time.Second),
+		},
+	))
+
+	// RFC 7591 动态客户端注册，需要admin访问令牌或初始访问令牌
 	server.AddRoute(rest.Route{
 		Method:  http.MethodPost,
-		Path:    "/api/client/register",
-		Handler: clientRegisterHandler(clientModel),
+		Path:    util.DynamicRegisterPath,
+		Handler: dynamicRegisterHandler(clientModel, scopeModel, registrar, supportedGrantTypes(srv.Config), c.Issuer),
+	})
+
+	// RFC 7592 使用注册访问令牌读取、更新、删除客户端
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    util.DynamicClientPath,
+		Handler: getDynamicClientHandler(clientModel, c.Issuer),
+	})
+	server.AddRoute(rest.Route{
+		Method:  http.MethodPut,
+		Path:    util.DynamicClientPath,
+		Handler: updateDynamicClientHandler(clientModel, scopeModel, supportedGrantTypes(srv.Config), c.Issuer),
+	})
+	server.AddRoute(rest.Route{
+		Method:  http.MethodDelete,
+		Path:    util.DynamicClientPath,
+		Handler: deleteDynamicClientHandler(clientModel, authorizationModel, redisStore),
 	})
 
 	// 登录页面
 	server.AddRoute(rest.Route{
 		Method:  http.MethodGet,
-		Path:    "/login",
-		Handler: loginHandler,
+		Path:    util.LoginPath,
+		Handler: loginHandler(userModel),
 	})
 
 	// 登录页面
 	server.AddRoute(rest.Route{
 		Method:  http.MethodPost,
-		Path:    "/login",
-		Handler: loginHandler,
+		Path:    util.LoginPath,
+		Handler: loginHandler(userModel),
 	})
 
 	// 授权页面
 	server.AddRoute(rest.Route{
 		Method:  http.MethodGet,
-		Path:    "/auth",
-		Handler: authHandler,
+		Path:    util.ConsentPath,
+		Handler: authHandler(clientModel, scopeModel),
+	})
+
+	// 授权管理页面
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    util.GrantsPath,
+		Handler: grantsPageHandler(authorizationModel, clientModel, redisStore),
+	})
+
+	// 授权管理页面
+	server.AddRoute(rest.Route{
+		Method:  http.MethodPost,
+		Path:    util.GrantsPath,
+		Handler: grantsPageHandler(authorizationModel, clientModel, redisStore),
+	})
+
+	// 已授权应用列表
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    util.GrantsAPIPath,
+		Handler: listGrantsHandler(authorizationModel, clientModel),
+	})
+
+	// 撤销应用授权
+	server.AddRoute(rest.Route{
+		Method:  http.MethodDelete,
+		Path:    util.GrantAPIPath,
+		Handler: revokeGrantHandler(authorizationModel, redisStore),
 	})
 
 	// OAuth2授权端点
 	server.AddRoute(rest.Route{
 		Method:  http.MethodGet,
-		Path:    "/oauth/authorize",
-		Handler: authorizeHandler(srv),
+		Path:    util.AuthorizePath,
+		Handler: authorizeHandler(srv, clientModel, scopeModel),
 	})
 
 	// OAuth2授权端点
 	server.AddRoute(rest.Route{
 		Method:  http.MethodPost,
-		Path:    "/oauth/authorize",
-		Handler: authorizeHandler(srv),
+		Path:    util.AuthorizePath,
+		Handler: authorizeHandler(srv, clientModel, scopeModel),
 	})
 
 	// OAuth2令牌端点
 	server.AddRoute(rest.Route{
 		Method:  http.MethodPost,
-		Path:    "/oauth/token",
+		Path:    util.TokenPath,
 		Handler: tokenHandler(srv),
 	})
 
+	// OAuth2令牌吊销端点
+	server.AddRoute(rest.Route{
+		Method:  http.MethodPost,
+		Path:    util.RevokePath,
+		Handler: revokeHandler(srv, redisStore),
+	})
+
+	// OAuth2令牌内省端点
+	server.AddRoute(rest.Route{
+		Method:  http.MethodPost,
+		Path:    util.IntrospectPath,
+		Handler: introspectHandler(srv),
+	})
+
 	// 用户信息端点
 	server.AddRoute(rest.Route{
 		Method:  http.MethodGet,
-		Path:    "/oauth/userinfo",
-		Handler: userInfoHandler(srv),
+		Path:    util.UserInfoPath,
+		Handler: userInfoHandler(srv, userModel, scopeModel),
+	})
+
+	// 签名公钥端点
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    util.JwksPath,
+		Handler: jwksHandler(keySet),
+	})
+
+	// 授权服务器元数据端点
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    util.OAuthMetadataPath,
+		Handler: discoveryHandler(server, srv, keySet, scopeModel, c),
 	})
 }
 
+// sensitiveFields 请求参数中不能写入日志的凭据和令牌
+var sensitiveFields = []string{
+	"client_secret", "password", "code", "code_verifier", "token", "refresh_token",
+	"consent_token", "registration_access_token",
+}
+
+// sensitiveHeaders 不能写入日志的请求头
+var sensitiveHeaders = []string{"Authorization", "Cookie"}
+
+// dumpRequest 输出请求内容用于调试，凭据、令牌和会话Cookie会被替换，非表单请求体只输出长度
 func dumpRequest(writer io.Writer, header string, r *http.Request) error {
-	data, err := httputil.DumpRequest(r, true)
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	dump := r.Clone(r.Context())
+	for _, name := range sensitiveHeaders {
+		if dump.Header.Get(name) != "" {
+			dump.Header.Set(name, "[REDACTED]")
+		}
+	}
+	dump.URL.RawQuery = redactValues(r.URL.Query()).Encode()
+	dump.RequestURI = dump.URL.RequestURI()
+
+	data, err := httputil.DumpRequest(dump, false)
 	if err != nil {
 		return err
 	}
 	writer.Write([]byte("\n" + header + ": \n"))
 	writer.Write(data)
-	return nil
-}
 
-func userAuthorizeHandler(w http.ResponseWriter, r *http.Request) (userID string, err error) {
-	if dumpvar {
-		_ = dumpRequest(os.Stdout, "userAuthorizeHandler", r)
-	}
-	store, err := session.Start(r.Context(), w, r)
-	if err != nil {
-		return
+	if len(body) > 0 {
+		values, err := url.ParseQuery(string(body))
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") && err == nil {
+			writer.Write([]byte(redactValues(values).Encode() + "\n"))
+		} else {
+			fmt.Fprintf(writer, "[%d bytes body omitted]\n", len(body))
+		}
 	}
+	return nil
+}
 
-	uid, ok := store.Get("LoggedInUserID")
-	log.Println("userAuthorizeHandler uid: ", uid)
-	if !ok {
-		if r.Form == nil {
-			r.ParseForm()
+func redactValues(values url.Values) url.Values {
+	for _, name := range sensitiveFields {
+		if _, ok := values[name]; ok {
+			values.Set(name, "[REDACTED]")
 		}
-		store.Set("ReturnUri", r.Form)
-		store.Save()
-
-		w.Header().Set("Location", "/login")
-		w.WriteHeader(http.StatusFound)
-		return
 	}
-
-	userID = uid.(string)
-	store.Delete("LoggedInUserID")
-	store.Save()
-	return
+	return values
 }
 
-func loginHandler(w http.ResponseWriter, r *http.Request) {
-	if dumpvar {
-		_ = dumpRequest(os.Stdout, "login", r)
-	}
-	store, err := session.Start(r.Context(), w, r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+// consentActionKey 上下文中保存用户在授权页面的选择
+type consentActionKey struct{}
 
-	if r.Method == "POST" {
-		if r.Form == nil {
-			if err := r.ParseForm(); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+func userAuthorizeHandler(authorizationModel model.AuthorizationModel, consentExpire int64) server.UserAuthorizationHandler {
+	return func(w http.ResponseWriter, r *http.Request) (userID string, err error) {
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "userAuthorizeHandler", r)
+		}
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			return
 		}
 
-		if r.Form.Get("username") == "test" && r.Form.Get("password") == "test" {
-			store.Set("LoggedInUserID", r.Form.Get("username"))
+		uid, ok := store.Get("LoggedInUserID")
+		log.Println("userAuthorizeHandler uid: ", uid)
+		if !ok {
+			if r.Form == nil {
+				r.ParseForm()
+			}
+			store.Set("ReturnUri", r.Form)
 			store.Save()
 
-			w.Header().Set("Location", "/auth")
+			w.Header().Set("Location", util.LoginPath)
 			w.WriteHeader(http.StatusFound)
 			return
-		} else {
-			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		}
+
+		// 用户尚未在授权页面做出选择
+		action, _ := r.Context().Value(consentActionKey{}).(string)
+		if action == "" {
+			// 有效期内已批准过请求的全部权限，跳过授权页面
+			var granted bool
+			granted, err = util.HasConsent(r.Context(), authorizationModel, r.FormValue("client_id"), uid.(string), r.FormValue("scope"), consentExpire)
+			if err != nil {
+				return "", err
+			}
+			if granted {
+				return uid.(string), nil
+			}
+
+			if r.Form == nil {
+				r.ParseForm()
+			}
+			store.Set("ReturnUri", r.Form)
+			store.Save()
+
+			w.Header().Set("Location", util.ConsentPath)
+			w.WriteHeader(http.StatusFound)
 			return
 		}
-	}
-	outputHTML(w, r, "static/login.html")
-}
 
-func authHandler(w http.ResponseWriter, r *http.Request) {
-	if dumpvar {
-		_ = dumpRequest(os.Stdout, "auth", r)
-	}
-	store, err := session.Start(nil, w, r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		// 登录状态保留在会话中，再次授权时无需重新登录
+		userID = uid.(string)
+
+		// 记录用户的授权决定
+		status := model.AuthorizationStatusApproved
+		if action == util.ConsentReject {
+			status = model.AuthorizationStatusRejected
+		}
+		_, err = authorizationModel.Insert(r.Context(), &model.Authorization{
+			ClientID: r.FormValue("client_id"),
+			UserID:   userID,
+			Scope:    r.FormValue("scope"),
+			Status:   status,
+		})
+		if err != nil {
+			return "", err
+		}
 
-	if _, ok := store.Get("LoggedInUserID"); !ok {
-		w.Header().Set("Location", "/login")
-		w.WriteHeader(http.StatusFound)
+		// 用户拒绝授权，重定向回客户端并携带access_denied错误
+		if status == model.AuthorizationStatusRejected {
+			return "", errors.ErrAccessDenied
+		}
 		return
 	}
-
-	outputHTML(w, r, "static/auth.html")
 }
 
-func outputHTML(w http.ResponseWriter, req *http.Request, filename string) {
-	file, err := os.Open(filename)
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+func loginHandler(userModel model.UserModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "login", r)
+		}
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if r.Method == "POST" {
+			if r.Form == nil {
+				if err := r.ParseForm(); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+
+			user, err := util.AuthenticateUser(r.Context(), userModel, r.Form.Get("username"), r.Form.Get("password"))
+			if err == util.ErrInvalidCredentials {
+				http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			store.Set("LoggedInUserID", user.ID)
+			store.Set("LoginTime", time.Now().Unix())
+
+			// 从授权管理页面跳转来的登录，完成后返回原页面；从授权请求跳转来的登录，完成后重新发起授权请求，
+			// 有效期内已批准过的权限不再展示授权页面
+			location := util.GrantsPath
+			if v, ok := store.Get("ReturnTo"); ok {
+				location = v.(string)
+				store.Delete("ReturnTo")
+			} else if v, ok := store.Get("ReturnUri"); ok {
+				location = util.AuthorizePath + "?" + v.(url.Values).Encode()
+				store.Delete("ReturnUri")
+			}
+			store.Save()
+
+			w.Header().Set("Location", location)
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		outputHTML(w, r, "static/login.html")
 	}
-	defer file.Close()
-	fi, _ := file.Stat()
-	http.ServeContent(w, req, file.Name(), fi.ModTime(), file)
 }
 
-func clientRegisterHandler(clientModel model.ClientModel) http.HandlerFunc {
+func authHandler(clientModel model.ClientModel, scopeModel model.ScopeModel) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct {
-			Name        string `json:"name"`
-			RedirectURL string `json:"redirect_url"`
-			GrantType   string `json:"grant_type"`
-			Scope       string `json:"scope"`
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "auth", r)
+		}
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if _, ok := store.Get("LoggedInUserID"); !ok {
+			w.Header().Set("Location", util.LoginPath)
+			w.WriteHeader(http.StatusFound)
 			return
 		}
 
-		client := &model.Client{
-			Name:        req.Name,
-			RedirectURL: req.RedirectURL,
-			GrantType:   req.GrantType,
-			Scope:       req.Scope,
+		// 根据保存的授权请求展示客户端名称和请求的权限
+		var form url.Values
+		if v, ok := store.Get("ReturnUri"); ok {
+			form = v.(url.Values)
+		}
+		if form == nil {
+			http.Error(w, errors.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
 		}
 
-		_, err := clientModel.Insert(r.Context(), client)
+		client, err := clientModel.FindByID(r.Context(), form.Get("client_id"))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, errors.ErrInvalidClient.Error(), http.StatusBadRequest)
 			return
 		}
 
-		resp := map[string]string{
-			"client_id":     client.ID,
-			"client_secret": client.Secret,
+		scopes, err := util.LoadScopes(r.Context(), scopeModel)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+		token := uuid.New().String()
+		store.Set("ConsentToken", token)
+		store.Save()
+
+		err = util.RenderTemplate(w, "static/auth.html", &util.ConsentPage{
+			ClientName: client.Name,
+			Scopes:     scopes.Describe(form.Get("scope")),
+			Token:      token,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 	}
 }
 
-func authorizeHandler(srv *server.Server) http.HandlerFunc {
+// grantsPageHandler 用户查看和撤销已授权的应用
+func grantsPageHandler(authorizationModel model.AuthorizationModel, clientModel model.ClientModel,
+	redisStore *util.RedisStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if dumpvar {
-			dumpRequest(os.Stdout, "authorize", r)
+			_ = dumpRequest(os.Stdout, "grants", r)
 		}
-
 		store, err := session.Start(r.Context(), w, r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		var form url.Values
-		if v, ok := store.Get("ReturnUri"); ok {
-			form = v.(url.Values)
+		uid, ok := store.Get("LoggedInUserID")
+		if !ok {
+			store.Set("ReturnTo", util.GrantsPath)
+			store.Save()
+
+			w.Header().Set("Location", util.LoginPath)
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		userID := uid.(string)
+
+		if r.Method == http.MethodPost {
+			// 校验表单令牌，防止跨站撤销授权
+			token, _ := store.Get("GrantsToken")
+			expected, _ := token.(string)
+			if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(r.PostFormValue("grants_token"))) != 1 {
+				http.Error(w, "invalid grants token", http.StatusForbidden)
+				return
+			}
+
+			err = util.RevokeGrant(r.Context(), authorizationModel, redisStore, userID, r.PostFormValue("client_id"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Location", util.GrantsPath)
+			w.WriteHeader(http.StatusFound)
+			return
 		}
-		r.Form = form
 
-		store.Delete("ReturnUri")
+		grants, err := util.ListGrants(r.Context(), authorizationModel, clientModel, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		token := uuid.New().String()
+		store.Set("GrantsToken", token)
 		store.Save()
 
-		err = srv.HandleAuthorizeRequest(w, r)
+		err = util.RenderTemplate(w, "static/grants.html", &util.GrantsPage{
+			Grants: grants,
+			Token:  token,
+		})
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	}
 }
 
-func tokenHandler(srv *server.Server) http.HandlerFunc {
+func listGrantsHandler(authorizationModel model.AuthorizationModel, clientModel model.ClientModel) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if dumpvar {
-			_ = dumpRequest(os.Stdout, "token", r)
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
 		}
 
-		err := srv.HandleTokenRequest(w, r)
+		grants, err := util.ListGrants(r.Context(), authorizationModel, clientModel, userID)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"grants": grants,
+		})
 	}
 }
 
-func userInfoHandler(srv *server.Server) http.HandlerFunc {
+func revokeGrantHandler(authorizationModel model.AuthorizationModel, redisStore *util.RedisStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if dumpvar {
-			_ = dumpRequest(os.Stdout, "userinfo", r)
+			_ = dumpRequest(os.Stdout, "revokeGrant", r)
 		}
 
-		token, err := srv.ValidationBearerToken(r)
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
+		}
+
+		err := util.RevokeGrant(r.Context(), authorizationModel, redisStore, userID, pathvar.Vars(r)["client_id"])
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// sessionUserID 获取会话中已登录的用户ID
+func sessionUserID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	store, err := session.Start(r.Context(), w, r)
+	if err != nil {
+		return "", false
+	}
+
+	uid, ok := store.Get("LoggedInUserID")
+	if !ok {
+		return "", false
+	}
+	return uid.(string), true
+}
 
-		data := map[string]interface{}{
-			"userid":   token.GetUserID(),
-			"username": "test_user",
-			"phone":    "13800138000",
+func outputHTML(w http.ResponseWriter, req *http.Request, filename string) {
+	file, err := os.Open(filename)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	defer file.Close()
+	fi, _ := file.Stat()
+	http.ServeContent(w, req, file.Name(), fi.ModTime(), file)
+}
+
+func clientRegisterHandler(clientModel model.ClientModel, scopeModel model.ScopeModel, registrar *clientRegistrar,
+	grantTypes []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registrant, ok := registrar.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Name         string   `json:"name"`
+			RedirectURL  string   `json:"redirect_url"`
+			RedirectURIs []string `json:"redirect_uris"`
+			GrantType    string   `json:"grant_type"`
+			Scope        string   `json:"scope"`
+			RequirePKCE  bool     `json:"require_pkce"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 校验回调地址
+		redirectURIs, err := util.NormalizeRedirectURIs(req.RedirectURL, req.RedirectURIs)
+		if err != nil {
+			http.Error(w, errors.ErrInvalidRedirectURI.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 校验权限范围均已注册
+		if allowed, err := checkScope(r.Context(), scopeModel, req.Scope, req.Scope); err != nil || !allowed {
+			http.Error(w, errors.ErrInvalidScope.Error(), http.StatusBadRequest)
+			return
+		}
+		if scopes, err := util.LoadScopes(r.Context(), scopeModel); err != nil || !registrant.AllowsScope(scopes, req.Scope) {
+			http.Error(w, errors.ErrInvalidScope.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 校验授权模式均为服务端支持的模式
+		allowedGrantTypes, err := util.NormalizeGrantTypes(req.GrantType, grantTypes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 生成客户端密钥，数据库只保存哈希
+		secret, hash, err := util.NewClientSecret()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		client := &model.Client{
+			Secret:      hash,
+			Name:        req.Name,
+			Scope:       req.Scope,
+			RequirePKCE: req.RequirePKCE,
+			Status:      registrant.ClientStatus(),
+		}
+		client.SetRedirectURIs(redirectURIs)
+		client.SetGrantTypes(allowedGrantTypes)
+
+		if err := registrant.Consume(r.Context()); err != nil {
+			util.WriteRegistrationError(w, err)
+			return
+		}
+		_, err = clientModel.Insert(r.Context(), client)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := map[string]string{
+			"client_id":     client.ID,
+			"client_secret": secret,
+			"status":        client.Status,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func listClientsHandler(clientModel model.ClientModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ClientListReq
+		if err := httpx.Parse(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		total, err := clientModel.Count(r.Context(), req.Status)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		clients, err := clientModel.FindPage(r.Context(), req.Status, req.Page, req.PageSize)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := types.ClientListResp{
+			Total:    total,
+			Page:     req.Page,
+			PageSize: req.PageSize,
+			Clients:  make([]types.ClientInfo, 0, len(clients)),
+		}
+		for _, client := range clients {
+			resp.Clients = append(resp.Clients, clientInfo(client))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func getClientHandler(clientModel model.ClientModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, ok := findClient(w, r, clientModel)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(clientInfo(client))
+	}
+}
+
+func updateClientHandler(clientModel model.ClientModel, scopeModel model.ScopeModel, grantTypes []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ClientUpdateReq
+		if err := httpx.Parse(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		redirectURIs, err := util.NormalizeRedirectURIs(req.RedirectURL, req.RedirectURIs)
+		if err != nil {
+			http.Error(w, errors.ErrInvalidRedirectURI.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if allowed, err := checkScope(r.Context(), scopeModel, req.Scope, req.Scope); err != nil || !allowed {
+			http.Error(w, errors.ErrInvalidScope.Error(), http.StatusBadRequest)
+			return
+		}
+
+		allowedGrantTypes, err := util.NormalizeGrantTypes(req.GrantType, grantTypes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		client, ok := findClient(w, r, clientModel)
+		if !ok {
+			return
+		}
+		if err := util.CheckPublicGrantTypes(client, allowedGrantTypes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		client.Name = req.Name
+		client.SetRedirectURIs(redirectURIs)
+		client.SetGrantTypes(allowedGrantTypes)
+		client.Scope = req.Scope
+		client.RequirePKCE = req.RequirePKCE
+		if err := clientModel.Update(r.Context(), client); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(clientInfo(client))
+	}
+}
+
+func deleteClientHandler(clientModel model.ClientModel, authorizationModel model.AuthorizationModel,
+	redisStore *util.RedisStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, ok := findClient(w, r, clientModel)
+		if !ok {
+			return
+		}
+
+		// 级联删除授权记录和已签发的令牌
+		err := util.DeleteClient(r.Context(), clientModel, authorizationModel, redisStore, client.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func resetClientSecretHandler(clientModel model.ClientModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, ok := findClient(w, r, clientModel)
+		if !ok {
+			return
+		}
+
+		secret, hash, err := util.NewClientSecret()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		client.Secret = hash
+		if err := clientModel.Update(r.Context(), client); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(types.ClientSecretResp{
+			ClientID:     client.ID,
+			ClientSecret: secret,
+		})
+	}
+}
+
+func dynamicRegisterHandler(clientModel model.ClientModel, scopeModel model.ScopeModel, registrar *clientRegistrar,
+	grantTypes []string, issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registrant, ok := registrar.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		var md util.ClientMetadata
+		if err := json.NewDecoder(r.Body).Decode(&md); err != nil {
+			util.WriteRegistrationError(w, &util.RegistrationError{
+				Code:        util.RegistrationErrInvalidClientMetadata,
+				Description: err.Error(),
+			})
+			return
+		}
+
+		reg, err := util.RegisterClient(r.Context(), clientModel, scopeModel, registrant, &md, grantTypes, util.IssuerURL(issuer))
+		if err != nil {
+			util.WriteRegistrationError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(reg)
+	}
+}
+
+func approveClientHandler(clientModel model.ClientModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, ok := findClient(w, r, clientModel)
+		if !ok {
+			return
+		}
+
+		if !client.IsActive() {
+			client.Status = model.ClientStatusActive
+			if err := clientModel.Update(r.Context(), client); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(clientInfo(client))
+	}
+}
+
+func createInitialTokenHandler(initialTokenModel model.InitialAccessTokenModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.InitialAccessTokenCreateReq
+		if err := httpx.Parse(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		t, token, err := util.NewInitialAccessToken(r.Context(), initialTokenModel, req.Description, req.ExpiresIn, req.MaxUses)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(types.InitialAccessTokenCreateResp{
+			Token:     token,
+			ID:        t.ID,
+			ExpiresAt: initialTokenInfo(t).ExpiresAt,
+			MaxUses:   t.MaxUses,
+		})
+	}
+}
+
+func listInitialTokensHandler(initialTokenModel model.InitialAccessTokenModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokens, err := initialTokenModel.FindAll(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := types.InitialAccessTokenListResp{Tokens: make([]types.InitialAccessTokenInfo, 0, len(tokens))}
+		for _, t := range tokens {
+			resp.Tokens = append(resp.Tokens, initialTokenInfo(t))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func deleteInitialTokenHandler(initialTokenModel model.InitialAccessTokenModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := initialTokenModel.Delete(r.Context(), pathvar.Vars(r)["id"]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// rotateSigningKeyHandler 按需轮换签名密钥，新密钥保存在Redis中，其他实例同步后使用
+func rotateSigningKeyHandler(keySet *util.KeySet, prepublish time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.RotateSigningKeyReq
+		if err := httpx.Parse(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		activateAt := time.Now()
+		if !req.Immediate {
+			activateAt = activateAt.Add(prepublish)
+		}
+		key, err := keySet.Rotate(r.Context(), activateAt)
+		if err == util.ErrRotationUnsupported {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("signing key %s scheduled, active after %s", key.ID, key.NotBefore.Format(time.RFC3339))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(types.RotateSigningKeyResp{
+			KeyID:     key.ID,
+			NotBefore: key.NotBefore.Unix(),
+		})
+	}
+}
+
+// initialTokenInfo 转换为接口返回的令牌信息，不包含令牌明文
+func initialTokenInfo(t *model.InitialAccessToken) types.InitialAccessTokenInfo {
+	info := types.InitialAccessTokenInfo{
+		ID:          t.ID,
+		Description: t.Description,
+		MaxUses:     t.MaxUses,
+		Uses:        t.Uses,
+		CreatedAt:   t.CreatedAt.Unix(),
+	}
+	if t.ExpiresAt.Valid {
+		info.ExpiresAt = t.ExpiresAt.Time.Unix()
+	}
+	return info
+}
+
+// clientRegistrar 客户端注册的调用方认证配置
+type clientRegistrar struct {
+	initialTokenModel model.InitialAccessTokenModel
+	adminVerifier     util.AdminVerifier
+	requireApproval   bool
+}
+
+// authenticate 校验注册请求携带的admin访问令牌或初始访问令牌，失败时返回401
+func (cr *clientRegistrar) authenticate(w http.ResponseWriter, r *http.Request) (*util.Registrant, bool) {
+	registrant, err := util.AuthenticateRegistrant(r.Context(), cr.initialTokenModel, cr.adminVerifier,
+		r.Header.Get("Authorization"), cr.requireApproval)
+	if err != nil {
+		util.WriteRegistrationError(w, err)
+		return nil, false
+	}
+	return registrant, true
+}
+
+func getDynamicClientHandler(clientModel model.ClientModel, issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, err := util.FindRegisteredClient(r.Context(), clientModel, pathvar.Vars(r)["client_id"],
+			r.Header.Get("Authorization"))
+		if err != nil {
+			util.WriteRegistrationError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(util.NewClientRegistration(client, util.IssuerURL(issuer)))
+	}
+}
+
+func updateDynamicClientHandler(clientModel model.ClientModel, scopeModel model.ScopeModel, grantTypes []string,
+	issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, err := util.FindRegisteredClient(r.Context(), clientModel, pathvar.Vars(r)["client_id"],
+			r.Header.Get("Authorization"))
+		if err != nil {
+			util.WriteRegistrationError(w, err)
+			return
+		}
+
+		// 请求体必须携带与地址一致的client_id
+		var req struct {
+			ClientID string `json:"client_id"`
+			util.ClientMetadata
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ClientID != client.ID {
+			util.WriteRegistrationError(w, &util.RegistrationError{
+				Code:        util.RegistrationErrInvalidClientMetadata,
+				Description: "client_id does not match",
+			})
+			return
+		}
+
+		if err := util.UpdateRegisteredClient(r.Context(), clientModel, scopeModel, client, &req.ClientMetadata, grantTypes); err != nil {
+			util.WriteRegistrationError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(util.NewClientRegistration(client, util.IssuerURL(issuer)))
+	}
+}
+
+func deleteDynamicClientHandler(clientModel model.ClientModel, authorizationModel model.AuthorizationModel,
+	redisStore *util.RedisStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, err := util.FindRegisteredClient(r.Context(), clientModel, pathvar.Vars(r)["client_id"],
+			r.Header.Get("Authorization"))
+		if err != nil {
+			util.WriteRegistrationError(w, err)
+			return
+		}
+
+		if err := util.DeleteClient(r.Context(), clientModel, authorizationModel, redisStore, client.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// checkScope 判断requested中的权限是否都已注册且在allowed范围内
+func checkScope(ctx context.Context, scopeModel model.ScopeModel, requested, allowed string) (bool, error) {
+	scopes, err := util.LoadScopes(ctx, scopeModel)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = scopes.Validate(requested, allowed)
+	if err == util.ErrInvalidScope {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// findClient 根据路径中的客户端ID查询客户端，不存在时直接返回404
+func findClient(w http.ResponseWriter, r *http.Request, clientModel model.ClientModel) (*model.Client, bool) {
+	client, err := clientModel.FindOne(r.Context(), pathvar.Vars(r)["id"])
+	if err == model.ErrNotFound {
+		http.Error(w, "client not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return client, true
+}
+
+// supportedGrantTypes 客户端可以注册的授权模式，服务端允许token响应类型时包含implicit
+func supportedGrantTypes(cfg *server.Config) []string {
+	grantTypes := make([]string, 0, len(cfg.AllowedGrantTypes)+1)
+	for _, gt := range cfg.AllowedGrantTypes {
+		grantTypes = append(grantTypes, gt.String())
+	}
+	for _, rt := range cfg.AllowedResponseTypes {
+		if rt == oauth2.Token {
+			grantTypes = append(grantTypes, oauth2.Implicit.String())
+		}
+	}
+	return grantTypes
+}
+
+// clientInfo 转换为接口返回的客户端信息，不包含密钥
+func clientInfo(client *model.Client) types.ClientInfo {
+	return types.ClientInfo{
+		ClientID:      client.ID,
+		Name:          client.Name,
+		RedirectURIs:  client.RedirectURIs(),
+		GrantType:     client.GrantType,
+		ResponseTypes: client.ResponseTypes(),
+		Scope:         client.Scope,
+		RequirePKCE:   client.RequirePKCE,
+		Status:        client.Status,
+		CreatedAt:     client.CreatedAt.Unix(),
+		UpdatedAt:     client.UpdatedAt.Unix(),
+	}
+}
+
+func authorizeHandler(srv *server.Server, clientModel model.ClientModel, scopeModel model.ScopeModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dumpvar {
+			dumpRequest(os.Stdout, "authorize", r)
+		}
+
+		store, err := session.Start(r.Context(), w, r)
+		if err != nil {
+			oautherr.Write(w, err)
+			return
+		}
+
+		// 授权页面提交的选择，恢复登录前保存的授权请求
+		if r.Method == http.MethodPost {
+			action := r.PostFormValue("action")
+			if action != util.ConsentApprove && action != util.ConsentReject {
+				oautherr.Write(w, errors.ErrInvalidRequest)
+				return
+			}
+
+			// 校验表单令牌，防止跨站提交授权
+			token, _ := store.Get("ConsentToken")
+			expected, _ := token.(string)
+			if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(r.PostFormValue("consent_token"))) != 1 {
+				oautherr.Write(w, oautherr.New(oautherr.AccessDenied, "invalid consent token"))
+				return
+			}
+
+			var form url.Values
+			if v, ok := store.Get("ReturnUri"); ok {
+				form = v.(url.Values)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), consentActionKey{}, action))
+			r.Form = form
+
+			store.Delete("ReturnUri")
+			store.Delete("ConsentToken")
+			store.Save()
+		}
+
+		// 客户端或回调地址无效时无法安全地重定向，直接返回错误页面；等待审批的客户端不能发起授权
+		client, err := clientModel.FindByID(r.Context(), r.FormValue("client_id"))
+		if err == model.ErrNotFound || (err == nil && !client.IsActive()) {
+			util.RenderErrorPage(w, oautherr.InvalidClient, "unknown client")
+			return
+		}
+		if err != nil {
+			oautherr.Write(w, err)
+			return
+		}
+
+		// 未携带redirect_uri时只有注册了唯一回调地址的客户端才能省略
+		redirectURI, err := util.ResolveRedirectURI(client.RedirectURIs(), r.FormValue("redirect_uri"))
+		if err != nil {
+			util.RenderErrorPage(w, oautherr.InvalidRequest, "invalid redirect_uri")
+			return
+		}
+		r.Form.Set("redirect_uri", redirectURI)
+
+		// 以下错误按RFC 6749 4.1.2.1重定向回客户端
+		responseType := r.FormValue("response_type")
+		if responseType == "" {
+			authorizeErrorRedirect(w, r, srv, oautherr.New(oautherr.InvalidRequest, "missing response_type"))
+			return
+		}
+		if !srv.CheckResponseType(oauth2.ResponseType(responseType)) {
+			authorizeErrorRedirect(w, r, srv, errors.ErrUnsupportedResponseType)
+			return
+		}
+
+		// 客户端只能使用注册时允许的响应类型，在用户登录和确认之前拒绝
+		if !client.AllowsResponseType(responseType) {
+			authorizeErrorRedirect(w, r, srv, errors.ErrUnauthorizedClient)
+			return
+		}
+
+		// 强制PKCE的客户端必须携带code_challenge
+		if client.RequirePKCE && r.FormValue("code_challenge") == "" {
+			authorizeErrorRedirect(w, r, srv, errors.ErrCodeChallengeRquired)
+			return
+		}
+
+		// 只能请求客户端注册的权限，补全隐含权限后再展示给用户确认
+		scopes, err := util.LoadScopes(r.Context(), scopeModel)
+		if err != nil {
+			authorizeErrorRedirect(w, r, srv, err)
+			return
+		}
+		scope, err := scopes.Validate(r.FormValue("scope"), client.Scope)
+		if err != nil {
+			authorizeErrorRedirect(w, r, srv, errors.ErrInvalidScope)
+			return
+		}
+
+		// 本服务不签发ID Token，拒绝显式请求的openid权限，使用注册的全部权限时去掉openid
+		if scopes.Contains(r.FormValue("scope"), "openid") {
+			authorizeErrorRedirect(w, r, srv, oautherr.New(oautherr.InvalidScope, "openid is not supported"))
+			return
+		}
+		r.Form.Set("scope", util.WithoutScope(scope, "openid"))
+
+		// 签发授权码时记录用户登录的时间作为auth_time
+		if loginTime, ok := store.Get("LoginTime"); ok {
+			r = r.WithContext(util.ContextWithAuthTime(r.Context(), loginTime.(int64)))
+		}
+
+		// go-oauth2校验请求参数失败时不会重定向，只返回错误
+		if err := srv.HandleAuthorizeRequest(w, r); err != nil {
+			authorizeErrorRedirect(w, r, srv, err)
+		}
+	}
+}
+
+// authorizeErrorRedirect 将授权错误重定向回已验证的回调地址并携带state，隐式授权的参数放在片段中。
+// 内部错误只返回server_error
+func authorizeErrorRedirect(w http.ResponseWriter, r *http.Request, srv *server.Server, err error) {
+	e := oautherr.From(err)
+	if e.Code == oautherr.ServerError {
+		log.Println("authorize failed:", err)
+	}
+	data := map[string]interface{}{"error": e.Code}
+	if e.Description != "" {
+		data["error_description"] = e.Description
+	}
+
+	responseType := oauth2.ResponseType(r.FormValue("response_type"))
+	if responseType != oauth2.Token {
+		responseType = oauth2.Code
+	}
+	location, err := srv.GetRedirectURI(&server.AuthorizeRequest{
+		RedirectURI:  r.FormValue("redirect_uri"),
+		ResponseType: responseType,
+		State:        r.FormValue("state"),
+	}, data)
+	if err != nil {
+		oautherr.Write(w, err)
+		return
+	}
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+func tokenHandler(srv *server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "token", r)
+		}
+
+		// 错误已按RFC 6749 5.2写入响应，这里只会返回写响应失败的错误
+		if err := srv.HandleTokenRequest(w, r); err != nil {
+			log.Println("write token response failed:", err)
+		}
+	}
+}
+
+func revokeHandler(srv *server.Server, redisStore *util.RedisStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "revoke", r)
+		}
+
+		client, err := authenticateClient(srv, r)
+		if err != nil {
+			oautherr.Write(w, errors.ErrInvalidClient)
+			return
+		}
+		clientID := client.GetID()
+
+		token := r.FormValue("token")
+		if token == "" {
+			oautherr.Write(w, oautherr.New(oautherr.InvalidRequest, "missing token"))
+			return
+		}
+
+		hint := r.FormValue("token_type_hint")
+		if hint != "" && hint != "access_token" && hint != "refresh_token" {
+			oautherr.Write(w, oautherr.New(oautherr.UnsupportedTokenType, ""))
+			return
+		}
+
+		// 令牌不存在时按RFC 7009视为吊销成功
+		ti := loadToken(r.Context(), srv, token, hint)
+		if ti == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// 只允许吊销颁发给自己的令牌
+		if ti.GetClientID() != clientID {
+			oautherr.Write(w, oautherr.New(oautherr.UnauthorizedClient, "token was not issued to this client"))
+			return
+		}
+
+		if ti.GetRefresh() == token {
+			// 吊销刷新令牌时吊销整个令牌族，包括此前轮换出的全部访问令牌和刷新令牌
+			if familyID := util.TokenFamily(ti); familyID != "" {
+				err = redisStore.RevokeFamily(r.Context(), familyID)
+			} else {
+				err = srv.Manager.RemoveRefreshToken(r.Context(), token)
+				if err == nil && ti.GetAccess() != "" {
+					err = srv.Manager.RemoveAccessToken(r.Context(), ti.GetAccess())
+				}
+			}
+		} else {
+			err = srv.Manager.RemoveAccessToken(r.Context(), token)
+		}
+		if err != nil {
+			oautherr.Write(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func introspectHandler(srv *server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "introspect", r)
+		}
+
+		// 公开客户端没有密钥，无法证明身份，不能内省令牌
+		client, err := authenticateClient(srv, r)
+		if err != nil {
+			oautherr.Write(w, errors.ErrInvalidClient)
+			return
+		}
+		if client.IsPublic() {
+			oautherr.Write(w, oautherr.New(oautherr.InvalidClient, "public clients cannot introspect tokens"))
+			return
+		}
+
+		// 无效、过期或已吊销的令牌只返回active=false
+		data := map[string]interface{}{"active": false}
+		ti, err := srv.Manager.LoadAccessToken(r.Context(), r.FormValue("token"))
+		if err == nil && ti != nil {
+			data = map[string]interface{}{
+				"active":    true,
+				"scope":     ti.GetScope(),
+				"client_id": ti.GetClientID(),
+				"sub":       ti.GetUserID(),
+				"aud":       []string{ti.GetClientID()},
+				"iat":       ti.GetAccessCreateAt().Unix(),
+				"exp":       ti.GetAccessCreateAt().Add(ti.GetAccessExpiresIn()).Unix(),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+// authenticateClient 验证调用方客户端并返回该客户端，支持Basic认证和表单参数两种方式
+func authenticateClient(srv *server.Server, r *http.Request) (oauth2.ClientInfo, error) {
+	if r.Form == nil {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+	}
+
+	clientID, clientSecret, err := server.ClientBasicHandler(r)
+	if err != nil {
+		clientID, clientSecret, err = server.ClientFormHandler(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := srv.Manager.GetClient(r.Context(), clientID)
+	if err != nil {
+		return nil, errors.ErrInvalidClient
+	}
+
+	// 密钥以哈希存储的客户端按哈希校验，其余客户端使用常量时间比较
+	if verifier, ok := client.(oauth2.ClientPasswordVerifier); ok {
+		if !verifier.VerifyPassword(clientSecret) {
+			return nil, errors.ErrInvalidClient
+		}
+	} else if subtle.ConstantTimeCompare([]byte(client.GetSecret()), []byte(clientSecret)) != 1 {
+		return nil, errors.ErrInvalidClient
+	}
+	return client, nil
+}
+
+// loadToken 按token_type_hint的顺序查找令牌，提示不准确时继续尝试另一种类型
+func loadToken(ctx context.Context, srv *server.Server, token, hint string) oauth2.TokenInfo {
+	loaders := []func(context.Context, string) (oauth2.TokenInfo, error){
+		srv.Manager.LoadAccessToken,
+		srv.Manager.LoadRefreshToken,
+	}
+	if hint == "refresh_token" {
+		loaders[0], loaders[1] = loaders[1], loaders[0]
+	}
+
+	for _, load := range loaders {
+		if ti, err := load(ctx, token); err == nil && ti != nil {
+			return ti
+		}
+	}
+	return nil
+}
+
+func jwksHandler(keySet *util.KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(util.NewJSONWebKeySet(keySet.PublishedKeys()...))
+	}
+}
+
+func discoveryHandler(server *rest.Server, srv *server.Server, keySet *util.KeySet, scopeModel model.ScopeModel, c config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 权限范围取自权限注册表，与授权时的校验保持一致
+		scopes, err := util.LoadScopes(r.Context(), scopeModel)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		opts := util.MetadataOptions{
+			Issuer: util.IssuerURL(c.Issuer),
+			Routes: server.Routes(),
+			Scopes: scopes.Names(),
+			Keys:   keySet.PublishedKeys(),
+		}
+		for _, gt := range srv.Config.AllowedGrantTypes {
+			opts.GrantTypes = append(opts.GrantTypes, gt.String())
+		}
+		for _, rt := range srv.Config.AllowedResponseTypes {
+			opts.ResponseTypes = append(opts.ResponseTypes, rt.String())
+		}
+		for _, ccm := range srv.Config.AllowedCodeChallengeMethods {
+			opts.CodeChallengeMethods = append(opts.CodeChallengeMethods, ccm.String())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(util.NewServerMetadata(opts))
+	}
+}
+
+// keySetAccessGenerate 使用密钥集合中当前生效的密钥签发JWT访问令牌
+type keySetAccessGenerate struct {
+	keys *util.KeySet
+}
+
+func (g *keySetAccessGenerate) Token(ctx context.Context, data *oauth2.GenerateBasic, isGenRefresh bool) (string, string, error) {
+	key, err := g.keys.SigningKey()
+	if err != nil {
+		return "", "", err
+	}
+	return generates.NewJWTAccessGenerate(key.ID, key.SignedKey, key.Method).Token(ctx, data, isGenRefresh)
+}
+
+func userInfoHandler(srv *server.Server, userModel model.UserModel, scopeModel model.ScopeModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dumpvar {
+			_ = dumpRequest(os.Stdout, "userinfo", r)
+		}
+
+		// 错误按RFC 6750 3.1输出
+		if _, ok := srv.AccessTokenResolveHandler(r); !ok {
+			oautherr.WriteBearer(w, oautherr.ErrMissingToken)
+			return
+		}
+		token, err := srv.ValidationBearerToken(r)
+		if err != nil {
+			oautherr.WriteBearer(w, err)
+			return
+		}
+
+		// 与go-zero服务一致，根据scope（含隐含权限）返回相应的用户信息
+		scopes, err := util.LoadScopes(r.Context(), scopeModel)
+		if err != nil {
+			oautherr.Write(w, err)
+			return
+		}
+		data := types.UserInfoResp{}
+
+		if scopes.Contains(token.GetScope(), "userid") {
+			data.UserID = token.GetUserID()
+		}
+
+		if scopes.Contains(token.GetScope(), "profile") {
+			user, err := userModel.FindOne(r.Context(), token.GetUserID())
+			if err == model.ErrNotFound {
+				oautherr.WriteBearer(w, oautherr.New(oautherr.InvalidToken, "access token is not associated with a user"))
+				return
+			}
+			if err != nil {
+				oautherr.Write(w, err)
+				return
+			}
+			data.Username = user.Username
+			data.Phone = user.Phone
 		}
 
 		w.Header().Set("Content-Type", "application/json")
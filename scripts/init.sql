@@ -6,11 +6,17 @@ USE oauth2;
 -- 客户端信息表
 CREATE TABLE IF NOT EXISTS `client` (
     `id` VARCHAR(64) NOT NULL COMMENT '客户端ID',
-    `secret` VARCHAR(128) NOT NULL COMMENT '客户端密钥',
+    `secret` VARCHAR(128) NOT NULL COMMENT '客户端密钥的加盐哈希',
     `name` VARCHAR(100) NOT NULL COMMENT '应用名称',
-    `redirect_url` VARCHAR(500) NOT NULL COMMENT '回调地址',
-    `grant_type` VARCHAR(50) NOT NULL COMMENT '支持的授权模式',
+    `redirect_url` VARCHAR(2000) NOT NULL COMMENT '回调地址，多个地址以空格分隔',
+    `grant_type` VARCHAR(200) NOT NULL COMMENT '允许的授权模式，多个以空格分隔',
     `scope` VARCHAR(200) NOT NULL COMMENT '请求的权限范围',
+    `require_pkce` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否强制使用PKCE',
+    `token_endpoint_auth_method` VARCHAR(32) NOT NULL DEFAULT 'client_secret_basic' COMMENT '令牌端点认证方式：client_secret_basic/client_secret_post/none',
+    `logo_uri` VARCHAR(500) NOT NULL DEFAULT '' COMMENT '应用图标地址',
+    `jwks` TEXT NOT NULL COMMENT '客户端公钥集合（JSON）',
+    `registration_token` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '注册访问令牌的加盐哈希',
+    `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT '状态：active/pending',
     `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`)
@@ -23,16 +29,66 @@ CREATE TABLE IF NOT EXISTS `authorization` (
     `user_id` VARCHAR(64) NOT NULL COMMENT '用户ID',
     `scope` VARCHAR(200) NOT NULL COMMENT '请求的权限范围',
     `code` VARCHAR(128) NOT NULL COMMENT '授权码',
-    `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '状态：pending/approved/rejected',
+    `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '状态：pending/approved/rejected/revoked',
     `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_code` (`code`),
-    KEY `idx_client_user` (`client_id`, `user_id`)
+    KEY `idx_client_user` (`client_id`, `user_id`),
+    KEY `idx_user` (`user_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限申请记录表';
 
--- 插入一些测试数据
-INSERT INTO `client` (`id`, `secret`, `name`, `redirect_url`, `grant_type`, `scope`) VALUES
-('trusted_client_001', 'trusted_secret_001', '可信应用1', 'http://localhost:3000/callback', 'authorization_code', 'userid profile'),
-('trusted_client_002', 'trusted_secret_002', '可信应用2', 'http://localhost:3001/callback', 'authorization_code', 'userid'),
-('test_client_001', 'test_secret_001', '测试应用1', 'http://localhost:3002/callback', 'authorization_code', 'userid profile'); 
\ No newline at end of file
+-- 用户信息表
+CREATE TABLE IF NOT EXISTS `user` (
+    `id` VARCHAR(64) NOT NULL COMMENT '用户ID',
+    `username` VARCHAR(64) NOT NULL COMMENT '用户名',
+    `password_hash` VARCHAR(255) NOT NULL COMMENT '密码哈希（bcrypt）',
+    `phone` VARCHAR(20) NOT NULL DEFAULT '' COMMENT '手机号',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户信息表';
+
+-- 权限范围表
+CREATE TABLE IF NOT EXISTS `scope` (
+    `name` VARCHAR(64) NOT NULL COMMENT '权限名称',
+    `description` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '授权页面展示的说明',
+    `sensitive` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为敏感权限',
+    `implies` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '隐含的其他权限，以空格分隔',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限范围表';
+
+-- 客户端注册使用的初始访问令牌表
+CREATE TABLE IF NOT EXISTS `initial_access_token` (
+    `id` VARCHAR(64) NOT NULL COMMENT '令牌ID，也是令牌明文的前缀',
+    `token_hash` VARCHAR(128) NOT NULL COMMENT '令牌密钥部分的加盐哈希',
+    `description` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '用途说明',
+    `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间，为空表示永不过期',
+    `max_uses` INT NOT NULL DEFAULT 1 COMMENT '最多可注册的客户端数量，0表示不限',
+    `uses` INT NOT NULL DEFAULT 0 COMMENT '已注册的客户端数量',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='初始访问令牌表';
+
+-- 插入一些测试数据，前三个客户端的密钥依次为 trusted_secret_001、trusted_secret_002、test_secret_001
+-- admin_client 通过 client_credentials 模式获取admin权限的访问令牌，用于注册和管理客户端。
+-- 它不预置密钥，部署后执行 oauth2-server -bootstrap-secrets 生成密钥，明文只输出到标准错误一次，数据库中只保存哈希
+INSERT INTO `client` (`id`, `secret`, `name`, `redirect_url`, `grant_type`, `scope`, `jwks`) VALUES
+('trusted_client_001', 'sha256$4d213523f85f0c303ff6abdc477effdb$98c68d440a4dd9c42f4983dc9343974347ab5651b01821466a9f9be6ea82001c', '可信应用1', 'http://localhost:3000/callback', 'authorization_code refresh_token', 'userid profile', ''),
+('trusted_client_002', 'sha256$f13b6fb76bc865c3cdf0e0ea1ddcfb2a$bc9a661e26eabb8d50903e6f2fd376b41036794ae3e776060d20df410e2d4f20', '可信应用2', 'http://localhost:3001/callback', 'authorization_code refresh_token', 'userid', ''),
+('test_client_001', 'sha256$b9009a9fafba947f62d40b5dc09f0b09$03e5c4c59c7b9e2d8062bc3b739c1f1aaf1a09c3050d28419c079ec20498212c', '测试应用1', 'http://localhost:3002/callback', 'authorization_code refresh_token', 'userid profile', ''),
+('admin_client', '', '管理后台', '', 'client_credentials', 'admin', '');
+
+-- 测试用户，密码为 test
+INSERT INTO `user` (`id`, `username`, `password_hash`, `phone`) VALUES
+('test_user', 'test', '$2a$10$/8TlODMh2I7R5o3k3ZzXFeHrCOXMy344XoX0hwiryhvA1T2IGnOde', '13800138000');
+
+-- 内置权限范围
+INSERT INTO `scope` (`name`, `description`, `sensitive`, `implies`) VALUES
+('openid', 'Verify your identity', 0, ''),
+('userid', 'Read your user ID', 0, ''),
+('profile', 'Read your user name and phone number', 1, 'userid'),
+('admin', 'Manage OAuth clients and registration tokens', 1, '');
@@ -0,0 +1,76 @@
+-- 已有数据库的升级脚本，新部署直接执行 init.sql 即可
+-- 按顺序执行尚未应用的部分
+
+USE oauth2;
+
+-- 客户端强制PKCE开关
+ALTER TABLE `client` ADD COLUMN `require_pkce` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否强制使用PKCE' AFTER `scope`;
+
+-- 用户信息表
+CREATE TABLE IF NOT EXISTS `user` (
+    `id` VARCHAR(64) NOT NULL COMMENT '用户ID',
+    `username` VARCHAR(64) NOT NULL COMMENT '用户名',
+    `password_hash` VARCHAR(255) NOT NULL COMMENT '密码哈希（bcrypt）',
+    `phone` VARCHAR(20) NOT NULL DEFAULT '' COMMENT '手机号',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户信息表';
+
+-- 用户授权管理：按用户查询授权记录，新增revoked状态
+ALTER TABLE `authorization` ADD KEY `idx_user` (`user_id`);
+ALTER TABLE `authorization` MODIFY COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '状态：pending/approved/rejected/revoked';
+
+-- 客户端密钥改为加盐哈希存储
+-- 服务启动时会自动将明文密钥迁移为哈希，迁移前后客户端使用原密钥均可正常认证，无需手动修改数据
+ALTER TABLE `client` MODIFY COLUMN `secret` VARCHAR(128) NOT NULL COMMENT '客户端密钥的加盐哈希';
+
+-- 每个客户端支持注册多个回调地址，以空格分隔存储
+ALTER TABLE `client` MODIFY COLUMN `redirect_url` VARCHAR(2000) NOT NULL COMMENT '回调地址，多个地址以空格分隔';
+
+-- 权限范围表，授权和签发令牌时请求的权限必须已在此注册
+CREATE TABLE IF NOT EXISTS `scope` (
+    `name` VARCHAR(64) NOT NULL COMMENT '权限名称',
+    `description` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '授权页面展示的说明',
+    `sensitive` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为敏感权限',
+    `implies` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '隐含的其他权限，以空格分隔',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限范围表';
+INSERT IGNORE INTO `scope` (`name`, `description`, `sensitive`, `implies`) VALUES
+('openid', 'Verify your identity', 0, ''),
+('userid', 'Read your user ID', 0, ''),
+('profile', 'Read your user name and phone number', 1, 'userid');
+
+-- 客户端只能使用注册的授权模式，多个模式以空格分隔
+-- 此前所有客户端都可以刷新令牌，为保持兼容，给已允许授权码模式的客户端补充refresh_token
+ALTER TABLE `client` MODIFY COLUMN `grant_type` VARCHAR(200) NOT NULL COMMENT '允许的授权模式，多个以空格分隔';
+UPDATE `client` SET `grant_type` = CONCAT(`grant_type`, ' refresh_token')
+WHERE CONCAT(' ', `grant_type`, ' ') LIKE '% authorization_code %'
+  AND CONCAT(' ', `grant_type`, ' ') NOT LIKE '% refresh_token %';
+
+-- RFC 7591/7592 动态客户端注册
+ALTER TABLE `client`
+    ADD COLUMN `token_endpoint_auth_method` VARCHAR(32) NOT NULL DEFAULT 'client_secret_basic' COMMENT '令牌端点认证方式：client_secret_basic/client_secret_post/none' AFTER `require_pkce`,
+    ADD COLUMN `logo_uri` VARCHAR(500) NOT NULL DEFAULT '' COMMENT '应用图标地址' AFTER `token_endpoint_auth_method`,
+    ADD COLUMN `jwks` TEXT NOT NULL COMMENT '客户端公钥集合（JSON）' AFTER `logo_uri`,
+    ADD COLUMN `registration_token` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '注册访问令牌的加盐哈希' AFTER `jwks`;
+
+-- 客户端注册需要admin权限的访问令牌或初始访问令牌，注册的客户端可以等待管理员审批
+ALTER TABLE `client` ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT '状态：active/pending' AFTER `registration_token`;
+CREATE TABLE IF NOT EXISTS `initial_access_token` (
+    `id` VARCHAR(64) NOT NULL COMMENT '令牌ID，也是令牌明文的前缀',
+    `token_hash` VARCHAR(128) NOT NULL COMMENT '令牌密钥部分的加盐哈希',
+    `description` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '用途说明',
+    `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间，为空表示永不过期',
+    `max_uses` INT NOT NULL DEFAULT 1 COMMENT '最多可注册的客户端数量，0表示不限',
+    `uses` INT NOT NULL DEFAULT 0 COMMENT '已注册的客户端数量',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='初始访问令牌表';
+INSERT IGNORE INTO `scope` (`name`, `description`, `sensitive`, `implies`) VALUES
+('admin', 'Manage OAuth clients and registration tokens', 1, '');
+-- 给管理后台使用的客户端开通admin权限，例如：
+-- UPDATE `client` SET `grant_type` = 'client_credentials', `scope` = 'admin' WHERE `id` = '<管理后台客户端ID>';
@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"oauth2-server/internal/config"
 	"oauth2-server/internal/model"
+	"oauth2-server/internal/util"
 	"os"
 	"time"
 
@@ -19,7 +21,6 @@ import (
 
 	"github.com/go-oauth2/oauth2/v4/errors"
 	"github.com/go-oauth2/oauth2/v4/manage"
-	"github.com/go-oauth2/oauth2/v4/models"
 	"github.com/go-oauth2/oauth2/v4/server"
 	"github.com/go-oauth2/oauth2/v4/store"
 	"github.com/go-session/session/v3"
@@ -31,6 +32,9 @@ var (
 	secretvar string
 	domainvar string
 	portvar   int
+
+	// userModel 登录和密码模式共用的用户存储
+	userModel model.UserModel
 )
 
 func init() {
@@ -58,35 +62,23 @@ func main() {
 
 	// 创建数据库连接
 	conn := sqlx.NewMysql("root:123456@tcp(192.168.59.132:3306)/oauth2?charset=utf8mb4&parseTime=True&loc=Local")
-	clientModel := model.NewClientModel(conn)
-
-	// 创建客户端存储
-	clientStore := store.NewClientStore()
-
-	// 从数据库加载客户端
-	clients, err := clientModel.FindAll(context.Background())
-
-	if err == nil {
-		for _, client := range clients {
-			log.Println(client.ID, " ", client.Secret, " ", client.RedirectURL)
-			clientStore.Set(client.ID, &models.Client{
-				ID:     client.ID,
-				Secret: client.Secret,
-				Domain: client.RedirectURL,
-			})
-		}
-	}
-	manager.MapClientStorage(clientStore)
+	clientModel := util.MustNewClientStore(model.NewClientModel(conn), config.ClientCacheConf{Expire: 60, Limit: 1000})
+	manager.MapClientStorage(clientModel)
+	userModel = model.NewUserModel(conn)
+	manager.SetValidateURIHandler(util.ValidateRedirectURIHandler)
 
 	srv := server.NewServer(server.NewConfig(), manager)
 
 	srv.SetPasswordAuthorizationHandler(func(ctx context.Context, clientID, username, password string) (userID string, err error) {
-		if username == "test" && password == "test" {
-			userID = "test"
-		} else {
-			err = errors.New("invalid username or password")
+		user, err := util.AuthenticateUser(ctx, userModel, username, password)
+		if err == util.ErrInvalidCredentials {
+			// 返回空的用户ID，go-oauth2按invalid_grant响应
+			return "", nil
 		}
-		return
+		if err != nil {
+			return "", err
+		}
+		return user.ID, nil
 	})
 
 	srv.SetUserAuthorizationHandler(userAuthorizeHandler)
@@ -222,17 +214,22 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		if r.Form.Get("username") == "test" && r.Form.Get("password") == "test" {
-			store.Set("LoggedInUserID", r.Form.Get("username"))
-			store.Save()
-
-			w.Header().Set("Location", "/auth")
-			w.WriteHeader(http.StatusFound)
-			return
-		} else {
+		user, err := util.AuthenticateUser(r.Context(), userModel, r.Form.Get("username"), r.Form.Get("password"))
+		if err == util.ErrInvalidCredentials {
 			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
 			return
 		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		store.Set("LoggedInUserID", user.ID)
+		store.Save()
+
+		w.Header().Set("Location", "/auth")
+		w.WriteHeader(http.StatusFound)
+		return
 	}
 	outputHTML(w, r, "static/login.html")
 }
@@ -7,22 +7,49 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 )
 
 const baseURL = "http://localhost:8080"
 
-// 测试客户端注册
+// 使用管理后台客户端获取admin权限的访问令牌，密钥为执行-bootstrap-secrets时输出的admin_client密钥，通过ADMIN_CLIENT_SECRET环境变量传入
+func adminToken() string {
+	data := url.Values{}
+	data.Add("grant_type", "client_credentials")
+	data.Add("scope", "admin")
+	data.Add("client_id", "admin_client")
+	data.Add("client_secret", os.Getenv("ADMIN_CLIENT_SECRET"))
+
+	resp, err := http.PostForm(baseURL+"/oauth/token", data)
+	if err != nil {
+		fmt.Printf("获取管理员令牌失败: %v\n", err)
+		return ""
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	json.NewDecoder(resp.Body).Decode(&token)
+	return token.AccessToken
+}
+
+// 测试客户端注册，注册接口需要admin访问令牌或初始访问令牌
 func testClientRegister() {
 	url := baseURL + "/api/client/register"
 	data := map[string]string{
 		"name":         "测试应用",
 		"redirect_url": "http://localhost:3000/callback",
-		"grant_type":   "authorization_code",
+		"grant_type":   "authorization_code refresh_token",
 		"scope":        "userid profile",
 	}
 
 	jsonData, _ := json.Marshal(data)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+adminToken())
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		fmt.Printf("客户端注册失败: %v\n", err)
 		return